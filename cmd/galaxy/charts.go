@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/otaviof/galaxy/pkg/galaxy"
+)
+
+var chartsCmd = &cobra.Command{
+	Use:   "charts",
+	Short: "Charts related sub-commands",
+}
+
+var chartsFetchCmd = &cobra.Command{
+	Use:   "fetch",
+	Run:   runChartsFetchCmd,
+	Short: "Add chart repositories to Helm home, and fetch charts employed by releases",
+	Long: `# galaxy charts fetch
+
+Add chart repositories declared on ".galaxy.yaml" to Helm home, download their indexes, and fetch
+every chart employed by releases planned for target environments into local cache, where it's
+found by "apply" later on. Exits with non-zero status when a chart version is not found.`,
+}
+
+var chartsOutdatedCmd = &cobra.Command{
+	Use:   "outdated",
+	Run:   runChartsOutdatedCmd,
+	Short: "List releases whose chart version lags behind the latest available",
+	Long: `# galaxy charts outdated
+
+Add chart repositories declared on ".galaxy.yaml" to Helm home, download their indexes, and list
+releases planned for target environments whose chart version, after environment "chartVersions"
+are applied, lags behind the latest version available on repositories.`,
+}
+
+func runChartsFetchCmd(cmd *cobra.Command, args []string) {
+	g := galaxyPlan()
+
+	fetches, err := g.FetchCharts()
+	g.Close()
+
+	fmt.Println(galaxy.ChartsTable(fetches))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] %s!\n", err)
+		os.Exit(1)
+	}
+}
+
+func runChartsOutdatedCmd(cmd *cobra.Command, args []string) {
+	g := galaxyPlan()
+
+	outdated, err := g.OutdatedCharts()
+	g.Close()
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] %s!\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(galaxy.OutdatedTable(outdated))
+}
+
+func init() {
+	flags := chartsCmd.PersistentFlags()
+
+	landscaperFlags(flags)
+
+	chartsCmd.AddCommand(chartsFetchCmd)
+	chartsCmd.AddCommand(chartsOutdatedCmd)
+	rootCmd.AddCommand(chartsCmd)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/otaviof/galaxy/pkg/galaxy"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Run:   runDoctorCmd,
+	Short: "Check connectivity and permissions required to apply an environment",
+	Long: `# galaxy doctor
+
+Check requirements to apply a target environment, printing a pass or fail checklist: kube-config and
+context, Kubernetes API reachability, RBAC permissions on each target namespace, Tiller pod
+readiness and version compatibility, Vault reachability and authentication, and charts availability
+on Helm home repositories. Exits with non-zero status when any check fails.`,
+}
+
+func runDoctorCmd(cmd *cobra.Command, args []string) {
+	g := galaxyPlan()
+
+	checks, err := g.Doctor()
+	g.Close()
+	if checks == nil && err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(galaxy.ChecksTable(checks))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] %s!\n", err)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	flags := doctorCmd.PersistentFlags()
+
+	flags.Bool("skip-secrets", false, "skip checking secret source")
+
+	kubernetesFlags(flags)
+	landscaperFlags(flags)
+	vaultFlags(flags)
+	secretSourceFlags(flags)
+
+	cobra.MarkFlagRequired(flags, "environment")
+	rootCmd.AddCommand(doctorCmd)
+}
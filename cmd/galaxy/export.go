@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/otaviof/galaxy/pkg/galaxy"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Run:   runExportCmd,
+	Short: "Export planned releases as helmfile or Argo CD applications",
+	Long: `# galaxy export
+
+Convert releases planned for a single environment into another GitOps tool format, keeping renamed
+namespaces and release names. Format "helmfile" generates a "helmfile.yaml", and "argocd" generates
+an Application manifest per release. Manifests are printed, or written on output directory.`,
+}
+
+func runExportCmd(cmd *cobra.Command, args []string) {
+	g := galaxyPlan()
+
+	files, err := g.Export(viper.GetString("format"), galaxy.ArgoCDSpec{
+		Namespace: viper.GetString("argocd-namespace"),
+		Project:   viper.GetString("argocd-project"),
+		Server:    viper.GetString("argocd-server"),
+		RepoURL:   viper.GetString("argocd-repo-url"),
+	})
+	g.Close()
+	exitOnError(err)
+
+	outputDir := viper.GetString("output-dir")
+	if outputDir != "" {
+		exitOnError(os.MkdirAll(outputDir, 0755))
+	}
+	for _, file := range files {
+		if outputDir == "" {
+			fmt.Print(string(file.Payload))
+			continue
+		}
+		filePath := path.Join(outputDir, file.Name)
+		log.Infof("Writing file '%s'", filePath)
+		exitOnError(ioutil.WriteFile(filePath, file.Payload, 0644))
+	}
+}
+
+func init() {
+	flags := exportCmd.PersistentFlags()
+
+	flags.String("format", galaxy.ExportHelmfile, "export format, \"helmfile\" or \"argocd\"")
+	flags.String("output-dir", "", "directory to write exported files, printed when empty")
+	flags.String("argocd-namespace", "argocd", "namespace where Argo CD is installed")
+	flags.String("argocd-project", "default", "Argo CD project")
+	flags.String("argocd-server", "https://kubernetes.default.svc",
+		"destination Kubernetes API server")
+	flags.String("argocd-repo-url", "", "git repository url, employed by local charts")
+
+	landscaperFlags(flags)
+
+	cobra.MarkFlagRequired(flags, "environment")
+	rootCmd.AddCommand(exportCmd)
+}
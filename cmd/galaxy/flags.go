@@ -0,0 +1,50 @@
+package main
+
+import (
+	"github.com/spf13/pflag"
+)
+
+// kubernetesFlags command-line flags related to Kubernetes API client.
+func kubernetesFlags(flags *pflag.FlagSet) {
+	flags.Bool("in-cluster", false, "running inside a Kubernetes cluster")
+	flags.String("kube-config", "", "alternative kube-config path")
+	flags.String("kube-context", "", "alternative Kubernetes context")
+	flags.String("kube-server", "", "Kubernetes API server address, used instead of kube-config")
+	flags.String("kube-token", "", "Kubernetes bearer token, required with --kube-server")
+	flags.String("kube-ca-cert", "", "Kubernetes API server CA certificate, used with --kube-server")
+}
+
+// landscaperFlags command-line flags related to Landscaper and Helm.
+func landscaperFlags(flags *pflag.FlagSet) {
+	flags.String("helm-home", "${HOME}/.helm", "helm home folder path")
+	flags.String("tiller-namespace", "kube-system", "Helm's Tiller namespace")
+	flags.Int("tiller-port", 44134, "Helm's Tiller service port")
+	flags.Int64("tiller-timeout", 30, "timeout on trying to reach tiller, in seconds")
+	flags.Bool("wait", false, "wait for resources to be ready")
+	flags.Int64("wait-timeout", 120, "timeout on waiting for resources, in seconds")
+	flags.String("disable", "", "actions to disable, as in \"create\", \"update\" or \"delete\"")
+	flags.String("override-file", "", "Landscaper configuration override file")
+	flags.Bool("tls", false, "enable TLS to reach Helm's Tiller")
+	flags.Bool("tls-verify", false, "enable TLS and verify Tiller's certificate")
+	flags.String("tls-ca-cert", "", "path to TLS CA certificate (default \"$HELM_HOME/ca.pem\")")
+	flags.String("tls-cert", "", "path to TLS certificate (default \"$HELM_HOME/cert.pem\")")
+	flags.String("tls-key", "", "path to TLS key (default \"$HELM_HOME/key.pem\")")
+	flags.String("tls-hostname", "", "server name used to verify Tiller's certificate hostname")
+}
+
+// vaultFlags command-line flags related to Vault API client.
+func vaultFlags(flags *pflag.FlagSet) {
+	flags.String("vault-addr", "http://127.0.0.1:8200", "Vault address")
+	flags.String("vault-token", "", "Vault access token")
+	flags.String("vault-role-id", "", "Vault AppRole role-id")
+	flags.String("vault-secret-id", "", "Vault AppRole secret-id")
+	flags.String("vault-kube-auth-path", "kubernetes", "Vault Kubernetes auth method mount path")
+	flags.String("vault-kube-role", "", "Vault Kubernetes auth method role, used when in-cluster")
+	flags.String("vault-kube-token-path", "/var/run/secrets/kubernetes.io/serviceaccount/token",
+		"Kubernetes service-account token path, used on Vault Kubernetes auth method")
+}
+
+// secretSourceFlags command-line flags related to alternative secret sources.
+func secretSourceFlags(flags *pflag.FlagSet) {
+	flags.String("secrets-passphrase", "", "passphrase to decrypt GPG encrypted secret files")
+}
@@ -2,7 +2,9 @@ package main
 
 import (
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -23,23 +25,33 @@ Please consider project repository to read more documentation:
 
 	https://github.com/otaviof/galaxy
 `,
+	PersistentPreRun: bindFlags,
 }
 
 // configFromEnv load runtime configuration from environment, which also includes command-line
 // parameters by using Viper.
 func configFromEnv() *galaxy.Config {
 	return &galaxy.Config{
-		DotGalaxyPath: viper.GetString("config"),
-		DryRun:        viper.GetBool("dry-run"),
-		Environments:  viper.GetString("environment"),
-		Namespaces:    viper.GetString("namespace"),
-		LogLevel:      viper.GetString("log-level"),
-		Raw:           viper.GetBool("raw"),
-		SkipSecrets:   viper.GetBool("skip-secrets"),
+		DotGalaxyPath:     viper.GetString("config"),
+		DryRun:            viper.GetBool("dry-run"),
+		Environments:      viper.GetString("environment"),
+		Namespaces:        viper.GetString("namespace"),
+		LogLevel:          viper.GetString("log-level"),
+		Raw:               viper.GetBool("raw"),
+		SkipSecrets:       viper.GetBool("skip-secrets"),
+		PruneSecrets:      viper.GetBool("prune-secrets"),
+		PrunePolicies:     viper.GetBool("prune-policies"),
+		PruneManifests:    viper.GetBool("prune-manifests"),
+		SecretsPassphrase: viper.GetString("secrets-passphrase"),
+		SecretsValidation: viper.GetString("secrets-validation"),
+		SkipPreflight:     viper.GetBool("skip-preflight"),
 		KubernetesConfig: &galaxy.KubernetesConfig{
 			InCluster:   viper.GetBool("in-cluster"),
 			KubeConfig:  viper.GetString("kube-config"),
 			KubeContext: viper.GetString("kube-context"),
+			KubeServer:  viper.GetString("kube-server"),
+			KubeToken:   viper.GetString("kube-token"),
+			KubeCaCert:  viper.GetString("kube-ca-cert"),
 		},
 		LandscaperConfig: &galaxy.LandscaperConfig{
 			DisabledStages:   viper.GetString("disable"),
@@ -50,12 +62,23 @@ func configFromEnv() *galaxy.Config {
 			TillerTimeout:    viper.GetInt64("tiller-timeout"),
 			WaitForResources: viper.GetBool("wait"),
 			WaitTimeout:      viper.GetInt64("wait-timeout"),
+			TillerTLSConfig: galaxy.TillerTLSConfig{
+				TLSEnable:     viper.GetBool("tls"),
+				TLSVerify:     viper.GetBool("tls-verify"),
+				TLSCaCert:     viper.GetString("tls-ca-cert"),
+				TLSCert:       viper.GetString("tls-cert"),
+				TLSKey:        viper.GetString("tls-key"),
+				TLSServerName: viper.GetString("tls-hostname"),
+			},
 		},
 		VaultHandlerConfig: &galaxy.VaultHandlerConfig{
-			VaultAddr:     viper.GetString("vault-addr"),
-			VaultToken:    viper.GetString("vault-token"),
-			VaultRoleID:   viper.GetString("vault-role-id"),
-			VaultSecretID: viper.GetString("vault-secret-id"),
+			VaultAddr:          viper.GetString("vault-addr"),
+			VaultToken:         viper.GetString("vault-token"),
+			VaultRoleID:        viper.GetString("vault-role-id"),
+			VaultSecretID:      viper.GetString("vault-secret-id"),
+			VaultKubeAuthPath:  viper.GetString("vault-kube-auth-path"),
+			VaultKubeRole:      viper.GetString("vault-kube-role"),
+			VaultKubeTokenPath: viper.GetString("vault-kube-token-path"),
 		},
 	}
 }
@@ -84,9 +107,31 @@ func galaxyPlan() *galaxy.Galaxy {
 		log.Fatal(err)
 	}
 
+	closeOnInterrupt(g)
 	return g
 }
 
+// closeOnInterrupt close shared clients, and tunnels to Tiller, when interrupted.
+func closeOnInterrupt(g *galaxy.Galaxy) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		sig := <-signals
+		log.Warnf("Received signal '%s', closing clients...", sig)
+		g.Close()
+		os.Exit(1)
+	}()
+}
+
+// bindFlags binds the flags of the command being executed, sub-commands may share flag names
+// therefore binding must happen only for the command in use.
+func bindFlags(cmd *cobra.Command, args []string) {
+	if err := viper.BindPFlags(cmd.Flags()); err != nil {
+		log.Fatal(err)
+	}
+}
+
 // init command-line arguments
 func init() {
 	flags := rootCmd.PersistentFlags()
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/otaviof/galaxy/pkg/galaxy"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Run:   runImportCmd,
+	Short: "Import Helm releases running on environment into release files",
+	Long: `# galaxy import
+
+List Helm releases deployed on target namespaces of a single environment, and write a Landscaper
+component file per release on the original namespace directory, with chart, release version and
+values as "configuration". Environment release prefix is stripped from release names, and releases
+already declared on repository are skipped. On dry-run files are not written.`,
+}
+
+func runImportCmd(cmd *cobra.Command, args []string) {
+	g := galaxyPlan()
+
+	imports, err := g.Import(viper.GetString("chart-repo"))
+	g.Close()
+
+	fmt.Println(galaxy.ImportsTable(imports))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] %s!\n", err)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	flags := importCmd.PersistentFlags()
+
+	flags.String("chart-repo", "stable",
+		"chart repository name, used when chart is not found on Helm home repositories index")
+
+	kubernetesFlags(flags)
+	landscaperFlags(flags)
+
+	cobra.MarkFlagRequired(flags, "environment")
+	rootCmd.AddCommand(importCmd)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/otaviof/galaxy/pkg/galaxy"
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Run:   runInitCmd,
+	Short: "Generate a new dot-galaxy file, with environments and namespaces",
+	Long: `# galaxy init
+
+Generate a new ".galaxy.yaml" with environments and namespaces informed, as comma separated lists,
+and create namespace directories under base directory. Each environment takes the first letter of
+its name as file suffix. An existing file is not overwritten.`,
+}
+
+var initNamespaceCmd = &cobra.Command{
+	Use:   "namespace <name>",
+	Run:   runInitNamespaceCmd,
+	Args:  cobra.ExactArgs(1),
+	Short: "Create namespace directory, and add it to dot-galaxy",
+}
+
+var initReleaseCmd = &cobra.Command{
+	Use:   "release <namespace> <name>",
+	Run:   runInitReleaseCmd,
+	Args:  cobra.ExactArgs(2),
+	Short: "Create a Landscaper component template on namespace directory",
+}
+
+// exitOnError prints error and exit with non-zero status, when error is informed.
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] %s!\n", err)
+		os.Exit(1)
+	}
+}
+
+func runInitCmd(cmd *cobra.Command, args []string) {
+	cfg := configFromEnv()
+	galaxy.SetLogLevel(cfg.LogLevel)
+
+	s := galaxy.NewScaffold(cfg.DotGalaxyPath)
+	exitOnError(s.InitDotGalaxy(
+		viper.GetString("base-dir"), cfg.GetEnvironments(), cfg.GetNamespaces()))
+	fmt.Printf("Dot-galaxy file written at '%s'\n", cfg.DotGalaxyPath)
+}
+
+func runInitNamespaceCmd(cmd *cobra.Command, args []string) {
+	cfg := configFromEnv()
+	galaxy.SetLogLevel(cfg.LogLevel)
+
+	s := galaxy.NewScaffold(cfg.DotGalaxyPath)
+	exitOnError(s.InitNamespace(args[0]))
+	fmt.Printf("Namespace '%s' added to '%s'\n", args[0], cfg.DotGalaxyPath)
+}
+
+func runInitReleaseCmd(cmd *cobra.Command, args []string) {
+	cfg := configFromEnv()
+	galaxy.SetLogLevel(cfg.LogLevel)
+
+	s := galaxy.NewScaffold(cfg.DotGalaxyPath)
+	file, err := s.InitRelease(args[0], args[1], viper.GetString("chart"))
+	exitOnError(err)
+	fmt.Printf("Release file written at '%s'\n", file)
+}
+
+func init() {
+	initCmd.Flags().String("base-dir", "namespaces", "base directory for namespace directories")
+	initReleaseCmd.Flags().String("chart", "", "chart reference, as in \"repo/name:version\"")
+
+	cobra.MarkFlagRequired(initReleaseCmd.Flags(), "chart")
+	initCmd.AddCommand(initNamespaceCmd)
+	initCmd.AddCommand(initReleaseCmd)
+	rootCmd.AddCommand(initCmd)
+}
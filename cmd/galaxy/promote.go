@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/otaviof/galaxy/pkg/galaxy"
+)
+
+var promoteCmd = &cobra.Command{
+	Use:   "promote",
+	Run:   runPromoteCmd,
+	Short: "Copy chart and release versions from an environment to another",
+	Long: `# galaxy promote
+
+Rewrite release files of target environment, as in "app@t.yaml", copying chart and release version
+from the same release on source environment, as in "app@d.yaml". Only the lines holding those values
+are changed, comments and formatting are preserved. Resulting diff is printed, and on dry-run files
+are not written.`,
+}
+
+func runPromoteCmd(cmd *cobra.Command, args []string) {
+	cfg := configFromEnv()
+	galaxy.SetLogLevel(cfg.LogLevel)
+	log.Debugf("cfg: %#v", cfg)
+
+	g := galaxy.NewGalaxy(bootstrap(cfg), cfg)
+	promotions, err := g.Promote(
+		viper.GetString("from"), viper.GetString("to"), viper.GetString("release"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] %s!\n", err)
+		os.Exit(1)
+	}
+	fmt.Print(galaxy.PromotionsDiff(promotions))
+}
+
+func init() {
+	flags := promoteCmd.PersistentFlags()
+
+	flags.String("from", "", "source environment")
+	flags.String("to", "", "target environment")
+	flags.String("release", "", "release name, as declared on release files")
+
+	cobra.MarkFlagRequired(flags, "from")
+	cobra.MarkFlagRequired(flags, "to")
+	rootCmd.AddCommand(promoteCmd)
+}
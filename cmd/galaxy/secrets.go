@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/otaviof/galaxy/pkg/galaxy"
+)
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Secrets related sub-commands",
+}
+
+var secretsCertsCmd = &cobra.Command{
+	Use:   "certs",
+	Run:   runSecretsCertsCmd,
+	Short: "List certificates of TLS secrets, sorted by expiry",
+	Long: `# galaxy secrets certs
+
+List every TLS secret ("kubernetes.io/tls") planned for a target environment, showing certificate
+subject, alternative names, issuer and expiry date, sorted by soonest expiry. Certificates are read
+from the secret source, or from Kubernetes secrets using "--from-cluster". Exits with non-zero status
+when a certificate expires within "--warn-days".`,
+}
+
+var secretsStatusCmd = &cobra.Command{
+	Use:   "status",
+	Run:   runSecretsStatusCmd,
+	Short: "Compare secret source contents with Kubernetes secrets",
+	Long: `# galaxy secrets status
+
+Inspect secret manifests planned for a target environment, comparing the secret source contents,
+Vault or local files, with Kubernetes secrets. Keys are reported as missing, outdated or extra,
+comparison is based on content hash and values are never displayed. Exits with non-zero status
+when secrets are not in sync.`,
+}
+
+func runSecretsStatusCmd(cmd *cobra.Command, args []string) {
+	g := galaxyPlan()
+
+	statuses, err := g.SecretsStatus()
+	g.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(galaxy.SecretsStatusTable(statuses))
+	if !galaxy.SecretsInSync(statuses) {
+		fmt.Fprintln(os.Stderr, "[ERROR] Secrets are not in sync!")
+		os.Exit(1)
+	}
+}
+
+func runSecretsCertsCmd(cmd *cobra.Command, args []string) {
+	g := galaxyPlan()
+
+	certs, err := g.Certificates(viper.GetBool("from-cluster"))
+	g.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(galaxy.CertificatesTable(certs))
+	warnDays := viper.GetInt("warn-days")
+	if galaxy.CertificatesExpiring(certs, warnDays) {
+		fmt.Fprintf(os.Stderr, "[ERROR] Certificates expiring within %d days!\n", warnDays)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	flags := secretsCmd.PersistentFlags()
+
+	kubernetesFlags(flags)
+	vaultFlags(flags)
+	secretSourceFlags(flags)
+
+	certsFlags := secretsCertsCmd.Flags()
+	certsFlags.Int("warn-days", 30, "exit with error when a certificate expires within days")
+	certsFlags.Bool("from-cluster", false, "read certificates from Kubernetes secrets")
+
+	secretsCmd.AddCommand(secretsStatusCmd)
+	secretsCmd.AddCommand(secretsCertsCmd)
+	rootCmd.AddCommand(secretsCmd)
+}
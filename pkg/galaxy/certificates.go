@@ -0,0 +1,145 @@
+package galaxy
+
+import (
+	"crypto/x509"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ryanuber/columnize"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	coreclient "k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset/typed/core/internalversion"
+)
+
+// Certificate details of a certificate found on a TLS secret.
+type Certificate struct {
+	Namespace string    // kubernetes namespace
+	Secret    string    // kubernetes secret name
+	File      string    // secret manifest file
+	Subject   string    // certificate subject
+	SANs      []string  // subject alternative names
+	Issuer    string    // certificate issuer
+	NotAfter  time.Time // expiry date
+}
+
+// Days until certificate expires, negative when already expired.
+func (c Certificate) Days() int {
+	return daysUntil(c.NotAfter)
+}
+
+// Certificates inspect TLS secrets in the plan, reading certificates from the secret source, or
+// from Kubernetes secrets when secrets client is informed.
+type Certificates struct {
+	logger  *log.Entry               // logger
+	source  SecretSource             // secret source, vault or local files
+	secrets coreclient.SecretsGetter // kubernetes secrets client, optional
+	ctxs    []*Context               // slice of context instances
+}
+
+// Inspect TLS secrets on namespace.
+func (c *Certificates) Inspect(ns string) ([]Certificate, error) {
+	var certs []Certificate
+
+	for _, ctx := range c.ctxs {
+		for _, secret := range ctx.Secrets[ns] {
+			for name, secretData := range secret.Manifest.Secrets {
+				var payload []byte
+				var cert *x509.Certificate
+				var err error
+
+				if secretData.Type != "kubernetes.io/tls" {
+					continue
+				}
+
+				logger := c.logger.WithFields(log.Fields{"namespace": ns, "secret": name})
+				logger.Info("Inspecting certificate...")
+
+				if payload, err = c.read(ns, name, secretData.Path); err != nil {
+					return nil, err
+				}
+				if cert, err = parseCertificate(payload); err != nil {
+					return nil, fmt.Errorf("secret '%s/%s' (file '%s'): %s", ns, name, secret.File, err)
+				}
+
+				certs = append(certs, Certificate{
+					Namespace: ns,
+					Secret:    name,
+					File:      secret.File,
+					Subject:   cert.Subject.CommonName,
+					SANs:      cert.DNSNames,
+					Issuer:    cert.Issuer.CommonName,
+					NotAfter:  cert.NotAfter,
+				})
+			}
+		}
+	}
+	return certs, nil
+}
+
+// read certificate payload from Kubernetes secret, or from secret source path.
+func (c *Certificates) read(ns, name, path string) ([]byte, error) {
+	var secret *core.Secret
+	var data map[string][]byte
+	var err error
+
+	if c.secrets != nil {
+		if secret, err = c.secrets.Secrets(ns).Get(name, metav1.GetOptions{}); err != nil {
+			return nil, err
+		}
+		data = secret.Data
+	} else {
+		if data, err = c.source.Read(path); err != nil {
+			return nil, err
+		}
+	}
+
+	payload, found := data["tls.crt"]
+	if !found {
+		return nil, fmt.Errorf("key 'tls.crt' is not found on secret '%s/%s'", ns, name)
+	}
+	return payload, nil
+}
+
+// CertificatesExpiring checks if any certificate expires within informed amount of days.
+func CertificatesExpiring(certs []Certificate, days int) bool {
+	for _, cert := range certs {
+		if cert.Days() < days {
+			return true
+		}
+	}
+	return false
+}
+
+// CertificatesTable format certificates as a table, sorted by soonest expiry. Informed slice is not
+// modified.
+func CertificatesTable(certs []Certificate) string {
+	sorted := make([]Certificate, len(certs))
+	copy(sorted, certs)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].NotAfter.Before(sorted[j].NotAfter)
+	})
+
+	lines := []string{"NAMESPACE | SECRET | SUBJECT | SANS | ISSUER | EXPIRY | DAYS"}
+	for _, cert := range sorted {
+		lines = append(lines, fmt.Sprintf("%s | %s | %s | %s | %s | %s | %d",
+			cert.Namespace, cert.Secret, cert.Subject, strings.Join(cert.SANs, ", "), cert.Issuer,
+			cert.NotAfter.Format("2006-01-02"), cert.Days(),
+		))
+	}
+	return columnize.SimpleFormat(lines)
+}
+
+// NewCertificates instantiate certificates inspector, when secrets client is informed certificates
+// are read from Kubernetes instead of secret source.
+func NewCertificates(
+	source SecretSource, secrets coreclient.SecretsGetter, ctxs []*Context) *Certificates {
+	return &Certificates{
+		logger:  log.WithField("type", "certificates"),
+		source:  source,
+		secrets: secrets,
+		ctxs:    ctxs,
+	}
+}
@@ -0,0 +1,54 @@
+package galaxy
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset/fake"
+)
+
+func TestCertificatesInspect(t *testing.T) {
+	dir, secretDir := fakeSecretsDir(t)
+	defer os.RemoveAll(dir)
+
+	cert, _ := fakeCertificate(t, "source.galaxy.local", time.Now().Add(10*24*time.Hour))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(secretDir, "tls.crt"), cert, 0600))
+
+	ctx := NewContext()
+	err := ctx.AddFile("ns1-d", "../../test/namespaces/ns1/ingress-secret.yaml")
+	assert.Nil(t, err)
+
+	c := NewCertificates(NewFileSecretSource(dir, "", ""), nil, []*Context{ctx})
+	certs, err := c.Inspect("ns1-d")
+	assert.Nil(t, err)
+	assert.Len(t, certs, 1)
+	assert.Equal(t, "source.galaxy.local", certs[0].Subject)
+	assert.Equal(t, []string{"source.galaxy.local"}, certs[0].SANs)
+	assert.True(t, CertificatesExpiring(certs, 30))
+	assert.False(t, CertificatesExpiring(certs, 5))
+
+	clusterCert, _ := fakeCertificate(t, "cluster.galaxy.local", time.Now().Add(time.Hour))
+	kube := fake.NewSimpleClientset(&core.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingress", Namespace: "ns1-d"},
+		Data:       map[string][]byte{"tls.crt": clusterCert},
+	})
+	c = NewCertificates(nil, kube.Core(), []*Context{ctx})
+	clusterCerts, err := c.Inspect("ns1-d")
+	assert.Nil(t, err)
+	assert.Len(t, clusterCerts, 1)
+	assert.Equal(t, "cluster.galaxy.local", clusterCerts[0].Subject)
+
+	certs = append(certs, clusterCerts...)
+	table := CertificatesTable(certs)
+	t.Logf("Certificates:\n%s", table)
+	assert.True(t, strings.Index(table, "cluster.galaxy.local") <
+		strings.Index(table, "source.galaxy.local"))
+	assert.Equal(t, "source.galaxy.local", certs[0].Subject)
+}
@@ -0,0 +1,352 @@
+package galaxy
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/ryanuber/columnize"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/helm/pkg/downloader"
+	"k8s.io/helm/pkg/getter"
+	"k8s.io/helm/pkg/helm/environment"
+	"k8s.io/helm/pkg/helm/helmpath"
+	"k8s.io/helm/pkg/repo"
+)
+
+const (
+	// ChartFetched chart has been downloaded
+	ChartFetched = "fetched"
+	// ChartCached chart is already present in local cache
+	ChartCached = "cached"
+	// ChartLocal chart is a local directory, not fetched
+	ChartLocal = "local"
+)
+
+// ChartFetch outcome of fetching a single chart reference.
+type ChartFetch struct {
+	Chart  string // chart reference, as in "repo/name:version"
+	Path   string // local chart archive path
+	Status string // fetched, cached or local
+}
+
+// ChartVersionUnknown version reported when repository index is not available.
+const ChartVersionUnknown = "unknown"
+
+// ChartOutdated release whose chart version lags behind the latest version available.
+type ChartOutdated struct {
+	Environment string // environment name
+	Namespace   string // target namespace
+	Release     string // release name
+	Chart       string // chart name, as in "repo/name"
+	Pinned      string // version or constraint declared
+	Resolved    string // version employed by release
+	Latest      string // latest version on repository index
+}
+
+// Charts manages chart repositories declared on dot-galaxy in Helm home, and fetches charts into
+// the same local cache employed by Landscaper's chart loader.
+type Charts struct {
+	logger   *log.Entry            // logger
+	home     helmpath.Home         // helm home
+	repos    []ChartRepositorySpec // chart repositories
+	local    *LocalCharts          // local chart directories
+	Fetches  []ChartFetch          // charts fetched
+	Outdated []ChartOutdated       // releases with outdated charts
+}
+
+// SyncRepositories add or update declared repositories in Helm home, and download their indexes.
+func (c *Charts) SyncRepositories() error {
+	var repoFile *repo.RepoFile
+	var err error
+
+	for _, dir := range []string{c.home.Repository(), c.home.Cache()} {
+		if err = os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	if fileExists(c.home.RepositoryFile()) {
+		if repoFile, err = repo.LoadRepositoriesFile(c.home.RepositoryFile()); err != nil {
+			return err
+		}
+	} else {
+		repoFile = repo.NewRepoFile()
+	}
+
+	for _, spec := range c.repos {
+		var chartRepo *repo.ChartRepository
+
+		logger := c.logger.WithFields(log.Fields{"repo": spec.Name, "url": spec.URL})
+		entry := spec.Entry(c.home)
+
+		if chartRepo, err = repo.NewChartRepository(entry, c.getters()); err != nil {
+			return err
+		}
+		logger.Info("Downloading repository index...")
+		if err = chartRepo.DownloadIndexFile(c.home.Cache()); err != nil {
+			return fmt.Errorf("repository '%s' at '%s': %s", spec.Name, spec.URL, err)
+		}
+
+		// credentials are kept in memory only, and informed again when fetching charts
+		persisted := *entry
+		persisted.Username, persisted.Password = "", ""
+		repoFile.Update(&persisted)
+	}
+
+	return repoFile.WriteFile(c.home.RepositoryFile(), 0600)
+}
+
+// credentials username and password of declared chart repository, with environment variables
+// expanded. Empty when repository is not declared on dot-galaxy.
+func (c *Charts) credentials(repoName string) (string, string) {
+	for _, spec := range c.repos {
+		if spec.Name == repoName {
+			entry := spec.Entry(c.home)
+			return entry.Username, entry.Password
+		}
+	}
+	return "", ""
+}
+
+// Fetch resolve informed chart references against repositories index, and download them into local
+// cache. Charts already cached are not downloaded again, and local chart directories are skipped.
+// Repository credentials are taken from dot-galaxy, since they are not kept in Helm home.
+func (c *Charts) Fetch(chartRefs []string) error {
+	for _, chartRef := range chartRefs {
+		name, version := splitChartRef(chartRef)
+		if isLocalChartRef(chartRef) {
+			c.Fetches = append(c.Fetches,
+				ChartFetch{Chart: chartRef, Path: c.local.ChartDir(name), Status: ChartLocal})
+			continue
+		}
+		parts := strings.Split(name, "/")
+		if len(parts) != 2 {
+			return fmt.Errorf("chart '%s': expected chart as 'repo/name'", chartRef)
+		}
+
+		dl := downloader.ChartDownloader{
+			HelmHome: c.home,
+			Out:      ioutil.Discard,
+			Verify:   downloader.VerifyNever,
+			Getters:  c.getters(),
+		}
+		dl.Username, dl.Password = c.credentials(parts[0])
+
+		logger := c.logger.WithField("chart", chartRef)
+		u, _, err := dl.ResolveChartVersion(name, version)
+		if err != nil {
+			return fmt.Errorf("chart '%s': %s", chartRef, err)
+		}
+
+		cacheDir := chartCacheDir(parts[0])
+		if err = os.MkdirAll(cacheDir, 0755); err != nil {
+			return err
+		}
+		chartPath := filepath.Join(cacheDir, filepath.Base(u.Path))
+		fetch := ChartFetch{Chart: chartRef, Path: chartPath, Status: ChartCached}
+
+		if !fileExists(chartPath) {
+			logger.Infof("Downloading chart from '%s'...", u.String())
+			if _, _, err = dl.DownloadTo(name, version, cacheDir); err != nil {
+				return fmt.Errorf("chart '%s': %s", chartRef, err)
+			}
+			fetch.Status = ChartFetched
+		}
+		c.Fetches = append(c.Fetches, fetch)
+	}
+	return nil
+}
+
+// FindOutdated inspect releases in informed contexts, recording the ones where chart version
+// resolved is older than the latest version on repository index. Releases without chart version,
+// and local charts, are skipped. When repository index is not available, release is recorded with
+// unknown versions.
+func (c *Charts) FindOutdated(envName string, ctxs []*Context) error {
+	indexes := make(map[string]*repo.IndexFile)
+	unavailable := make(map[string]bool)
+
+	for _, ctx := range ctxs {
+		var namespaces []string
+
+		for ns := range ctx.Releases {
+			namespaces = append(namespaces, ns)
+		}
+		sort.Strings(namespaces)
+
+		for _, ns := range namespaces {
+			for _, release := range ctx.Releases[ns] {
+				var index *repo.IndexFile
+				var resolved, latest *repo.ChartVersion
+				var found bool
+				var err error
+
+				chartRef := release.Component.Release.Chart
+				name, version := splitChartRef(chartRef)
+				if version == "" || isLocalChartRef(chartRef) {
+					continue
+				}
+				parts := strings.Split(name, "/")
+				if len(parts) != 2 {
+					return fmt.Errorf("chart '%s': expected chart as 'repo/name'", chartRef)
+				}
+
+				outdated := ChartOutdated{
+					Environment: envName,
+					Namespace:   ns,
+					Release:     release.Component.Name,
+					Chart:       name,
+					Pinned:      version,
+					Resolved:    ChartVersionUnknown,
+					Latest:      ChartVersionUnknown,
+				}
+
+				if unavailable[parts[0]] {
+					c.Outdated = append(c.Outdated, outdated)
+					continue
+				}
+				if index, found = indexes[parts[0]]; !found {
+					if index, err = repo.LoadIndexFile(c.home.CacheIndex(parts[0])); err != nil {
+						c.logger.Warnf("Index of repository '%s' is not available: %s", parts[0], err)
+						unavailable[parts[0]] = true
+						c.Outdated = append(c.Outdated, outdated)
+						continue
+					}
+					indexes[parts[0]] = index
+				}
+				if resolved, err = index.Get(parts[1], version); err != nil {
+					return fmt.Errorf("chart '%s': %s", chartRef, err)
+				}
+				if latest, err = index.Get(parts[1], ""); err != nil {
+					return fmt.Errorf("chart '%s': %s", chartRef, err)
+				}
+				if !versionLessThan(resolved.Version, latest.Version) {
+					continue
+				}
+
+				outdated.Resolved = resolved.Version
+				outdated.Latest = latest.Version
+				c.Outdated = append(c.Outdated, outdated)
+			}
+		}
+	}
+	return nil
+}
+
+// getters helm getter providers, based on helm home.
+func (c *Charts) getters() getter.Providers {
+	return getter.All(environment.EnvSettings{Home: c.home})
+}
+
+// chartCacheDir local cache directory per repository, mirrors the location used by Landscaper to
+// store downloaded charts, so fetched charts are not downloaded again on apply.
+func chartCacheDir(repoName string) string {
+	return filepath.Join(os.TempDir(), "landscaper", repoName)
+}
+
+// splitChartRef split chart reference into chart name and optional version.
+func splitChartRef(chartRef string) (string, string) {
+	if parts := strings.SplitN(chartRef, ":", 2); len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return chartRef, ""
+}
+
+// resolveChartRef resolve chart version constraint, as in "repo/name:~1.2", against repository
+// index in Helm home, returning chart reference with the matching version. References without
+// version or with an exact version are kept.
+func resolveChartRef(home helmpath.Home, chartRef string) (string, error) {
+	var index *repo.IndexFile
+	var cv *repo.ChartVersion
+	var err error
+
+	name, version := splitChartRef(chartRef)
+	if version == "" || isExactVersion(version) {
+		return chartRef, nil
+	}
+	parts := strings.Split(name, "/")
+	if len(parts) != 2 {
+		return "", fmt.Errorf("chart '%s': expected chart as 'repo/name'", chartRef)
+	}
+
+	if index, err = repo.LoadIndexFile(home.CacheIndex(parts[0])); err != nil {
+		return "", fmt.Errorf("chart '%s': %s", chartRef, err)
+	}
+	if cv, err = index.Get(parts[1], version); err != nil {
+		return "", fmt.Errorf("chart '%s': %s", chartRef, err)
+	}
+	return fmt.Sprintf("%s:%s", name, cv.Version), nil
+}
+
+// isExactVersion checks if version is a complete semantic version, instead of a constraint.
+func isExactVersion(version string) bool {
+	_, err := semver.NewVersion(version)
+	return err == nil && strings.Count(version, ".") >= 2
+}
+
+// versionLessThan checks if version is older than other, versions not following semver are not
+// comparable, and therefore not older.
+func versionLessThan(version, other string) bool {
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return false
+	}
+	o, err := semver.NewVersion(other)
+	if err != nil {
+		return false
+	}
+	return v.LessThan(o)
+}
+
+// chartRefs unique chart references employed by releases in informed contexts, sorted.
+func chartRefs(ctxs []*Context) []string {
+	var refs []string
+
+	for _, ctx := range ctxs {
+		for _, releases := range ctx.Releases {
+			for _, release := range releases {
+				if !stringSliceContains(refs, release.Component.Release.Chart) {
+					refs = append(refs, release.Component.Release.Chart)
+				}
+			}
+		}
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+// ChartsTable format charts fetched as a table.
+func ChartsTable(fetches []ChartFetch) string {
+	lines := []string{"CHART | STATUS | PATH"}
+	for _, fetch := range fetches {
+		lines = append(lines, fmt.Sprintf("%s | %s | %s", fetch.Chart, fetch.Status, fetch.Path))
+	}
+	return columnize.SimpleFormat(lines)
+}
+
+// OutdatedTable format releases with outdated charts as a table.
+func OutdatedTable(outdated []ChartOutdated) string {
+	lines := []string{"ENVIRONMENT | NAMESPACE | RELEASE | CHART | PINNED | RESOLVED | LATEST"}
+	for _, o := range outdated {
+		lines = append(lines, fmt.Sprintf("%s | %s | %s | %s | %s | %s | %s",
+			o.Environment, o.Namespace, o.Release, o.Chart, o.Pinned, o.Resolved, o.Latest))
+	}
+	return columnize.SimpleFormat(lines)
+}
+
+// NewCharts instantiate charts manager for Helm home and declared repositories, local charts are
+// relative to base directory.
+func NewCharts(baseDir, helmHome string, repos []ChartRepositorySpec) *Charts {
+	return &Charts{
+		logger:   log.WithFields(log.Fields{"type": "charts", "helmHome": helmHome}),
+		home:     helmpath.Home(helmHome),
+		repos:    repos,
+		local:    NewLocalCharts(baseDir, helmHome),
+		Fetches:  []ChartFetch{},
+		Outdated: []ChartOutdated{},
+	}
+}
@@ -0,0 +1,194 @@
+package galaxy
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ldsc "github.com/Eneco/landscaper/pkg/landscaper"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/helm/helmpath"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/repo"
+)
+
+// fakeChartRepository serves a chart repository, containing "app" chart on informed versions, from
+// a temporary directory.
+func fakeChartRepository(t *testing.T, versions ...string) (*httptest.Server, string) {
+	dir, err := ioutil.TempDir("", "galaxy-chart-repo")
+	assert.Nil(t, err)
+
+	server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+
+	for _, version := range versions {
+		_, err = chartutil.Save(&chart.Chart{
+			Metadata: &chart.Metadata{ApiVersion: "v1", Name: "app", Version: version},
+		}, dir)
+		assert.Nil(t, err)
+	}
+
+	index, err := repo.IndexDirectory(dir, server.URL)
+	assert.Nil(t, err)
+	assert.Nil(t, index.WriteFile(filepath.Join(dir, "index.yaml"), 0644))
+
+	return server, dir
+}
+
+func TestChartsFetch(t *testing.T) {
+	server, repoDir := fakeChartRepository(t, "0.1.0")
+	defer server.Close()
+	defer os.RemoveAll(repoDir)
+
+	home, err := ioutil.TempDir("", "galaxy-helm-home")
+	assert.Nil(t, err)
+	defer os.RemoveAll(home)
+
+	repoName := filepath.Base(repoDir)
+	defer os.RemoveAll(chartCacheDir(repoName))
+
+	c := NewCharts("", home, []ChartRepositorySpec{{Name: repoName, URL: server.URL}})
+	assert.Nil(t, c.SyncRepositories())
+
+	repoFile, err := repo.LoadRepositoriesFile(filepath.Join(home, "repository", "repositories.yaml"))
+	assert.Nil(t, err)
+	assert.True(t, repoFile.Has(repoName))
+
+	assert.Nil(t, c.Fetch([]string{repoName + "/app:0.1.0"}))
+	assert.Len(t, c.Fetches, 1)
+	assert.Equal(t, ChartFetched, c.Fetches[0].Status)
+	assert.True(t, fileExists(c.Fetches[0].Path))
+
+	// already in local cache
+	assert.Nil(t, c.Fetch([]string{repoName + "/app"}))
+	assert.Equal(t, ChartCached, c.Fetches[1].Status)
+
+	assert.NotNil(t, c.Fetch([]string{repoName + "/app:9.9.9"}))
+	assert.NotNil(t, c.Fetch([]string{"app"}))
+
+	// local chart directories are not fetched
+	assert.Nil(t, c.Fetch([]string{"./charts/app"}))
+	assert.Equal(t, ChartLocal, c.Fetches[2].Status)
+	assert.Equal(t, "charts/app", c.Fetches[2].Path)
+}
+
+func TestChartsChartRefs(t *testing.T) {
+	ctx := NewContext()
+	assert.Nil(t, ctx.AddFile("ns1", "../../test/namespaces/ns1/app1.yaml"))
+	assert.Nil(t, ctx.AddFile("ns2", "../../test/namespaces/ns1/app1.yaml"))
+
+	assert.Equal(t, []string{"stable/grafana:3.3.0"}, chartRefs([]*Context{ctx}))
+}
+
+func TestChartsCredentials(t *testing.T) {
+	server, repoDir := fakeChartRepository(t, "0.1.0")
+	server.Close()
+	defer os.RemoveAll(repoDir)
+
+	// repository requiring basic auth, index pointing to it
+	files := http.FileServer(http.Dir(repoDir))
+	authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		files.ServeHTTP(w, r)
+	}))
+	defer authServer.Close()
+	index, err := repo.IndexDirectory(repoDir, authServer.URL)
+	assert.Nil(t, err)
+	assert.Nil(t, index.WriteFile(filepath.Join(repoDir, "index.yaml"), 0644))
+
+	home, err := ioutil.TempDir("", "galaxy-helm-home")
+	assert.Nil(t, err)
+	defer os.RemoveAll(home)
+
+	repoName := filepath.Base(repoDir)
+	defer os.RemoveAll(chartCacheDir(repoName))
+
+	os.Setenv("GALAXY_TEST_REPO_PASSWORD", "pass")
+	defer os.Unsetenv("GALAXY_TEST_REPO_PASSWORD")
+	c := NewCharts("", home, []ChartRepositorySpec{{
+		Name: repoName, URL: authServer.URL, Username: "user", Password: "${GALAXY_TEST_REPO_PASSWORD}",
+	}})
+	assert.Nil(t, c.SyncRepositories())
+
+	// credentials are not written on helm home
+	repoFilePath := filepath.Join(home, "repository", "repositories.yaml")
+	info, err := os.Stat(repoFilePath)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	repoFile, err := repo.LoadRepositoriesFile(repoFilePath)
+	assert.Nil(t, err)
+	assert.Len(t, repoFile.Repositories, 1)
+	assert.Empty(t, repoFile.Repositories[0].Username)
+	assert.Empty(t, repoFile.Repositories[0].Password)
+
+	assert.Nil(t, c.Fetch([]string{repoName + "/app:0.1.0"}))
+	assert.Equal(t, ChartFetched, c.Fetches[0].Status)
+}
+
+func TestChartsFindOutdated(t *testing.T) {
+	server, repoDir := fakeChartRepository(t, "0.1.0", "0.1.1", "0.2.0")
+	defer server.Close()
+	defer os.RemoveAll(repoDir)
+
+	home, err := ioutil.TempDir("", "galaxy-helm-home")
+	assert.Nil(t, err)
+	defer os.RemoveAll(home)
+
+	repoName := filepath.Base(repoDir)
+	c := NewCharts("", home, []ChartRepositorySpec{{Name: repoName, URL: server.URL}})
+	assert.Nil(t, c.SyncRepositories())
+
+	ctx := NewContext()
+	for name, chartRef := range map[string]string{
+		"exact":      repoName + "/app:0.1.0",
+		"constraint": repoName + "/app:~0.1",
+		"latest":     repoName + "/app:0.2.0",
+		"unpinned":   repoName + "/app",
+		"local":      "./charts/app:0.1.0",
+	} {
+		ctx.Releases["ns1"] = append(ctx.Releases["ns1"], Release{
+			Namespace: "ns1",
+			Component: &Component{Name: name, Release: &ldsc.Release{Chart: chartRef}},
+		})
+	}
+
+	assert.Nil(t, c.FindOutdated("dev", []*Context{ctx}))
+	assert.Len(t, c.Outdated, 2)
+
+	resolved := map[string]string{}
+	for _, o := range c.Outdated {
+		assert.Equal(t, "0.2.0", o.Latest)
+		resolved[o.Release] = o.Resolved
+	}
+	assert.Equal(t, map[string]string{"exact": "0.1.0", "constraint": "0.1.1"}, resolved)
+
+	// repositories without index are reported as unknown
+	ctx.Releases["ns2"] = []Release{{
+		Namespace: "ns2",
+		Component: &Component{Name: "missing", Release: &ldsc.Release{Chart: "missing/app:0.1.0"}},
+	}}
+	c.Outdated = []ChartOutdated{}
+	assert.Nil(t, c.FindOutdated("dev", []*Context{ctx}))
+	assert.Len(t, c.Outdated, 3)
+	assert.Equal(t, "missing", c.Outdated[2].Release)
+	assert.Equal(t, ChartVersionUnknown, c.Outdated[2].Resolved)
+	assert.Equal(t, ChartVersionUnknown, c.Outdated[2].Latest)
+
+	// constraints are resolved against repository index
+	ref, err := resolveChartRef(helmpath.Home(home), repoName+"/app:~0.1")
+	assert.Nil(t, err)
+	assert.Equal(t, repoName+"/app:0.1.1", ref)
+
+	ref, err = resolveChartRef(helmpath.Home(home), repoName+"/app:0.1.0")
+	assert.Nil(t, err)
+	assert.Equal(t, repoName+"/app:0.1.0", ref)
+
+	_, err = resolveChartRef(helmpath.Home(home), repoName+"/app:~1.0")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,165 @@
+package galaxy
+
+import (
+	"fmt"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// inClusterKey cache key employed for in-cluster clients.
+const inClusterKey = "<in-cluster>"
+
+// Clients cache of Kubernetes and Helm API clients, keyed by Kubernetes context, and Tiller TLS
+// configuration for Helm, plus the authenticated Vault client. Clients are created once and shared
+// by every namespace and environment, Helm tunnels and Vault token renewal are kept until Close is
+// called.
+type Clients struct {
+	logger   *log.Entry             // logger
+	kubeCfg  *KubernetesConfig      // base kubernetes configuration
+	cfg      *LandscaperConfig      // landscaper configuration, for helm clients
+	vaultCfg *VaultHandlerConfig    // vault configuration
+	mutex    sync.Mutex             // protects cache maps
+	kube     map[string]*KubeClient // kubernetes clients per context
+	helm     map[string]*HelmClient // helm clients per context and tls configuration
+	vault    *VaultClient           // authenticated vault client
+}
+
+// key for informed Kubernetes context.
+func (c *Clients) key(kubeContext string) string {
+	if c.kubeCfg.InCluster {
+		return inClusterKey
+	}
+	if c.kubeCfg.KubeServer != "" {
+		return c.kubeCfg.KubeServer
+	}
+	return kubeContext
+}
+
+// helmKey for informed Kubernetes context and Tiller TLS configuration, so environments sharing a
+// context with different TLS settings don't share Helm clients.
+func (c *Clients) helmKey(kubeContext string, tlsCfg *TillerTLSConfig) string {
+	key := c.key(kubeContext)
+	if tlsCfg == nil || !tlsCfg.Enabled() {
+		return key
+	}
+	return fmt.Sprintf("%s (tls: %+v)", key, *tlsCfg)
+}
+
+// Kube returns the Kubernetes API client for context, creating it when not cached yet.
+func (c *Clients) Kube(kubeContext string) (*KubeClient, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.loadKube(kubeContext)
+}
+
+// loadKube look up cache or load a new Kubernetes API client, lock must be held.
+func (c *Clients) loadKube(kubeContext string) (*KubeClient, error) {
+	key := c.key(kubeContext)
+	if kubeClient, found := c.kube[key]; found {
+		return kubeClient, nil
+	}
+
+	c.logger.Infof("Creating Kubernetes API client for context '%s'", key)
+	kubeCfg := *c.kubeCfg
+	kubeCfg.KubeContext = kubeContext
+
+	kubeClient := NewKubeClient(&kubeCfg)
+	if err := kubeClient.Load(); err != nil {
+		return nil, err
+	}
+	c.kube[key] = kubeClient
+	return kubeClient, nil
+}
+
+// Helm returns the Helm API client for context and TLS configuration, creating it, and the tunnel to
+// Tiller, when not cached yet. TLS configuration is optional.
+func (c *Clients) Helm(kubeContext string, tlsCfg *TillerTLSConfig) (*HelmClient, error) {
+	var kubeClient *KubeClient
+	var err error
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := c.helmKey(kubeContext, tlsCfg)
+	if helmClient, found := c.helm[key]; found {
+		return helmClient, nil
+	}
+	if kubeClient, err = c.loadKube(kubeContext); err != nil {
+		return nil, err
+	}
+
+	c.logger.Infof("Creating Helm API client for context '%s'", c.key(kubeContext))
+	helmClient := NewHelmClient(
+		c.cfg.HelmHome,
+		c.cfg.TillerNamespace,
+		c.cfg.TillerPort,
+		c.cfg.TillerTimeout,
+		tlsCfg,
+		kubeClient,
+	)
+	if err = helmClient.Load(); err != nil {
+		helmClient.Close()
+		return nil, err
+	}
+	c.helm[key] = helmClient
+	return helmClient, nil
+}
+
+// Vault returns the Vault API client, authenticating and starting token renewal a single time, so
+// pre-flight checks and secrets share the same session.
+func (c *Clients) Vault() (*VaultClient, error) {
+	var err error
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.vault != nil {
+		return c.vault, nil
+	}
+
+	c.logger.Info("Authenticating on Vault...")
+	vaultClient := NewVaultClient(c.vaultCfg, c.kubeCfg)
+	if err = vaultClient.Load(); err != nil {
+		return nil, err
+	}
+	if err = vaultClient.Login(); err != nil {
+		return nil, err
+	}
+	if err = vaultClient.StartRenewal(); err != nil {
+		return nil, err
+	}
+	c.vault = vaultClient
+	return vaultClient, nil
+}
+
+// Close Helm tunnels, stop Vault token renewal and clean up cache.
+func (c *Clients) Close() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for key, helmClient := range c.helm {
+		c.logger.Debugf("Closing Helm client '%s'", key)
+		helmClient.Close()
+	}
+	if c.vault != nil {
+		c.vault.Stop()
+	}
+	c.kube = make(map[string]*KubeClient)
+	c.helm = make(map[string]*HelmClient)
+	c.vault = nil
+}
+
+// NewClients instantiate an empty clients cache.
+func NewClients(
+	kubeCfg *KubernetesConfig, cfg *LandscaperConfig, vaultCfg *VaultHandlerConfig) *Clients {
+	return &Clients{
+		logger:   log.WithField("type", "clients"),
+		kubeCfg:  kubeCfg,
+		cfg:      cfg,
+		vaultCfg: vaultCfg,
+		kube:     make(map[string]*KubeClient),
+		helm:     make(map[string]*HelmClient),
+	}
+}
@@ -0,0 +1,87 @@
+package galaxy
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeKubeConfig writes a temporary kube-config with two contexts, "a" and "b".
+func fakeKubeConfig(t *testing.T) string {
+	f, err := ioutil.TempFile("", "galaxy-kube-config")
+	assert.Nil(t, err)
+	_, err = f.WriteString(`---
+apiVersion: v1
+kind: Config
+clusters:
+  - name: a
+    cluster:
+      server: https://127.0.0.1:6443
+  - name: b
+    cluster:
+      server: https://127.0.0.2:6443
+users:
+  - name: user
+    user:
+      token: token
+contexts:
+  - name: a
+    context:
+      cluster: a
+      user: user
+  - name: b
+    context:
+      cluster: b
+      user: user
+current-context: a
+`)
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+	return f.Name()
+}
+
+func TestClientsKube(t *testing.T) {
+	kubeConfig := fakeKubeConfig(t)
+	defer os.Remove(kubeConfig)
+
+	cfg := NewConfig()
+	cfg.KubeConfig = kubeConfig
+	clients := NewClients(cfg.KubernetesConfig, cfg.LandscaperConfig, cfg.VaultHandlerConfig)
+
+	a, err := clients.Kube("a")
+	assert.Nil(t, err)
+	assert.Equal(t, "https://127.0.0.1:6443", a.RestCfg.Host)
+
+	cached, err := clients.Kube("a")
+	assert.Nil(t, err)
+	assert.True(t, a == cached)
+
+	b, err := clients.Kube("b")
+	assert.Nil(t, err)
+	assert.Equal(t, "https://127.0.0.2:6443", b.RestCfg.Host)
+	assert.False(t, a == b)
+
+	clients.Close()
+	afterClose, err := clients.Kube("a")
+	assert.Nil(t, err)
+	assert.False(t, a == afterClose)
+}
+
+func TestClientsHelmKey(t *testing.T) {
+	cfg := NewConfig()
+	clients := NewClients(cfg.KubernetesConfig, cfg.LandscaperConfig, cfg.VaultHandlerConfig)
+
+	disabled := &TillerTLSConfig{TLSCaCert: "ca.pem"}
+	a := &TillerTLSConfig{TLSEnable: true, TLSCert: "a.pem"}
+	b := &TillerTLSConfig{TLSEnable: true, TLSCert: "b.pem"}
+
+	assert.Equal(t, "ctx", clients.helmKey("ctx", nil))
+	assert.Equal(t, "ctx", clients.helmKey("ctx", disabled))
+	assert.NotEqual(t, clients.helmKey("ctx", nil), clients.helmKey("ctx", a))
+	assert.NotEqual(t, clients.helmKey("ctx", a), clients.helmKey("ctx", b))
+	assert.Equal(t, clients.helmKey("ctx", a), clients.helmKey("ctx", &TillerTLSConfig{
+		TLSEnable: true, TLSCert: "a.pem",
+	}))
+}
@@ -2,18 +2,25 @@ package galaxy
 
 import (
 	"os"
+	"path"
 	"strings"
 )
 
 // Config runtime configuration, command-line arguments.
 type Config struct {
-	DotGalaxyPath string // path to dot-galaxy file
-	DryRun        bool   // dry-run flag
-	LogLevel      string // log verboseness
-	Raw           bool   // prints the output on raw mode
-	Environments  string // target environment names, comma separated
-	Namespaces    string // target namespaces, comma separated
-	SkipSecrets   bool   // skip handling secrets
+	DotGalaxyPath     string // path to dot-galaxy file
+	DryRun            bool   // dry-run flag
+	LogLevel          string // log verboseness
+	Raw               bool   // prints the output on raw mode
+	Environments      string // target environment names, comma separated
+	Namespaces        string // target namespaces, comma separated
+	SkipSecrets       bool   // skip handling secrets
+	PruneSecrets      bool   // delete secrets owned by galaxy, no longer planned
+	PrunePolicies     bool   // delete policies owned by galaxy, no longer planned
+	PruneManifests    bool   // delete manifest objects owned by galaxy, no longer planned
+	SecretsPassphrase string // passphrase for encrypted secret files
+	SecretsValidation string // secrets validation policy, "fail", "warn" or "skip"
+	SkipPreflight     bool   // skip pre-flight checks before apply
 
 	*KubernetesConfig
 	*LandscaperConfig
@@ -35,6 +42,9 @@ type KubernetesConfig struct {
 	KubeConfig  string // path to alternative ~/.kube/config
 	KubeContext string // kubernetes context
 	InCluster   bool   // inside a Kubernetes cluster
+	KubeServer  string // kubernetes api server address, instead of kube-config
+	KubeToken   string // kubernetes bearer token
+	KubeCaCert  string // kubernetes api server ca certificate path
 }
 
 // LandscaperConfig runtime configuration related to Landscaper.
@@ -47,6 +57,38 @@ type LandscaperConfig struct {
 	WaitForResources bool   // wait for resources flag
 	WaitTimeout      int64  // wait for resources timeout
 	DisabledStages   string // comma separated list of disabled stages
+
+	TillerTLSConfig
+}
+
+// TillerTLSConfig TLS configuration to reach Helm's Tiller.
+type TillerTLSConfig struct {
+	TLSEnable     bool   // enable tls
+	TLSVerify     bool   // enable tls and verify tiller certificate
+	TLSCaCert     string // path to ca certificate
+	TLSCert       string // path to client certificate
+	TLSKey        string // path to client key
+	TLSServerName string // tiller server name, to verify certificate hostname
+}
+
+// Enabled checks if TLS is in use.
+func (t TillerTLSConfig) Enabled() bool {
+	return t.TLSEnable || t.TLSVerify
+}
+
+// WithDefaults returns a copy of TLS configuration, with certificate and key paths defaulting to
+// files in Helm home, as Helm's command-line does.
+func (t TillerTLSConfig) WithDefaults(home string) *TillerTLSConfig {
+	if t.TLSCaCert == "" {
+		t.TLSCaCert = path.Join(home, "ca.pem")
+	}
+	if t.TLSCert == "" {
+		t.TLSCert = path.Join(home, "cert.pem")
+	}
+	if t.TLSKey == "" {
+		t.TLSKey = path.Join(home, "key.pem")
+	}
+	return &t
 }
 
 // GetDisabledStages return a slice of strings based on disabled stages.
@@ -56,10 +98,19 @@ func (l *LandscaperConfig) GetDisabledStages() []string {
 
 // VaultHandlerConfig configuration related to vault-handler.
 type VaultHandlerConfig struct {
-	VaultAddr     string // vault api endpoint
-	VaultToken    string // vault token
-	VaultRoleID   string // vault approle role-id
-	VaultSecretID string // vault approle secret-id
+	VaultAddr          string // vault api endpoint
+	VaultToken         string // vault token
+	VaultRoleID        string // vault approle role-id
+	VaultSecretID      string // vault approle secret-id
+	VaultKubeAuthPath  string // vault kubernetes auth method mount path
+	VaultKubeRole      string // vault kubernetes auth method role
+	VaultKubeTokenPath string // path to kubernetes service-account token (jwt)
+}
+
+// UseKubernetesAuth checks if Vault authentication should use Kubernetes auth method, which is only
+// the case when running inside the cluster, with a role, and no token is informed.
+func (v *VaultHandlerConfig) UseKubernetesAuth(inCluster bool) bool {
+	return inCluster && v.VaultKubeRole != "" && v.VaultToken == ""
 }
 
 // splitOnComma using strings.Split, or empty slice in case of empty string.
@@ -73,11 +124,12 @@ func splitOnComma(str string) []string {
 // NewConfig with default values.
 func NewConfig() *Config {
 	return &Config{
-		LogLevel:      "error",
-		DryRun:        false,
-		DotGalaxyPath: ".galaxy.yaml",
-		Environments:  "",
-		Namespaces:    "",
+		LogLevel:          "error",
+		DryRun:            false,
+		DotGalaxyPath:     ".galaxy.yaml",
+		Environments:      "",
+		Namespaces:        "",
+		SecretsValidation: SecretsValidationWarn,
 		KubernetesConfig: &KubernetesConfig{
 			KubeConfig: os.Getenv("KUBECONFIG"),
 		},
@@ -89,7 +141,9 @@ func NewConfig() *Config {
 			WaitTimeout:     60,
 		},
 		VaultHandlerConfig: &VaultHandlerConfig{
-			VaultAddr: "http://127.0.0.1:8200",
+			VaultAddr:          "http://127.0.0.1:8200",
+			VaultKubeAuthPath:  "kubernetes",
+			VaultKubeTokenPath: "/var/run/secrets/kubernetes.io/serviceaccount/token",
 		},
 	}
 }
@@ -43,3 +43,18 @@ func TestConfigGetNamespaces(t *testing.T) {
 	cfg.Namespaces = "one,two"
 	assert.Equal(t, []string{"one", "two"}, cfg.GetNamespaces())
 }
+
+func TestConfigTillerTLSConfig(t *testing.T) {
+	tlsCfg := TillerTLSConfig{}
+	assert.False(t, tlsCfg.Enabled())
+
+	tlsCfg.TLSVerify = true
+	assert.True(t, tlsCfg.Enabled())
+
+	tlsCfg.TLSCert = "/tls/cert.pem"
+	withDefaults := tlsCfg.WithDefaults("/helm")
+	assert.Equal(t, "/helm/ca.pem", withDefaults.TLSCaCert)
+	assert.Equal(t, "/tls/cert.pem", withDefaults.TLSCert)
+	assert.Equal(t, "/helm/key.pem", withDefaults.TLSKey)
+	assert.Equal(t, "", tlsCfg.TLSCaCert)
+}
@@ -1,22 +1,59 @@
 package galaxy
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	ldsc "github.com/Eneco/landscaper/pkg/landscaper"
 	log "github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
 
 	vh "github.com/otaviof/vault-handler/pkg/vault-handler"
 )
 
+const (
+	// FileKindRelease Landscaper release file kind
+	FileKindRelease = "release"
+	// FileKindSecret Vault-Handler secret manifest file kind
+	FileKindSecret = "secret"
+	// FileKindPolicy namespace policy manifest file kind
+	FileKindPolicy = "policy"
+	// FileKindManifest plain Kubernetes manifest file kind
+	FileKindManifest = "manifest"
+)
+
+// FileKinds kinds of files found on namespace directories.
+var FileKinds = []string{FileKindRelease, FileKindSecret, FileKindPolicy, FileKindManifest}
+
 // Context of releases per namespace directory, a context is unique per environment.
 type Context struct {
-	logger   *log.Entry                  // logger
-	Releases map[string][]Release        // releases per namespace (key)
-	Secrets  map[string][]SecretManifest // secret manifests per namespace (key)
+	logger    *log.Entry                  // logger
+	Releases  map[string][]Release        // releases per namespace (key)
+	Secrets   map[string][]SecretManifest // secret manifests per namespace (key)
+	Policies  map[string][]PolicyManifest // namespace policy manifests per namespace (key)
+	Manifests map[string][]Manifest       // raw kubernetes manifests per namespace (key)
+	Overlays  map[string][]Overlay        // kustomize overlays per namespace (key)
+	FileKinds map[string][]string         // file name patterns per file kind (key)
+}
+
+// fileHeader attributes declaring the kind of file.
+type fileHeader struct {
+	APIVersion string `yaml:"apiVersion"` // kubernetes api version
+	Kind       string `yaml:"kind"`       // file kind, or kubernetes kind
+}
+
+// secretFile Vault-Handler secret manifest, optionally declaring file kind.
+type secretFile struct {
+	Kind        string `yaml:"kind"` // file kind
+	vh.Manifest `yaml:",inline"`
 }
 
 // Release binds together a file and a Landscaper component
@@ -33,6 +70,27 @@ type SecretManifest struct {
 	Manifest  *vh.Manifest // vault-handler manifest
 }
 
+// PolicyManifest Kubernetes namespace policy manifest, as in ResourceQuota or NetworkPolicy.
+type PolicyManifest struct {
+	Namespace string                     // release namespace
+	File      string                     // manifest file path
+	Object    *unstructured.Unstructured // kubernetes object
+}
+
+// Manifest plain Kubernetes manifest file, with one or more objects.
+type Manifest struct {
+	Namespace string                       // release namespace
+	File      string                       // manifest file path
+	Objects   []*unstructured.Unstructured // kubernetes objects
+}
+
+// Overlay kustomize overlay directory, and the objects it builds.
+type Overlay struct {
+	Namespace string                       // release namespace
+	Dir       string                       // overlay directory path
+	Objects   []*unstructured.Unstructured // kubernetes objects built
+}
+
 // ReleaseRenamer method to rename releases in this context
 type ReleaseRenamer func(ns, name string) (string, error)
 
@@ -41,18 +99,21 @@ type NamespaceRenamer func(ns string) string
 
 // Component contains information about the release, configuration and secrets of a component
 type Component struct {
+	Kind          string              `json:"-" yaml:"kind"`
 	Name          string              `json:"name" validate:"nonzero,max=51"`
 	Namespace     string              `json:"namespace"`
 	Release       *ldsc.Release       `json:"release" validate:"nonzero"`
 	Configuration ldsc.Configuration  `json:"configuration"`
 	Environments  ldsc.Configurations `json:"environments"`
 	SecretsRaw    interface{}         `json:"secrets" yaml:"secrets"`
+	Annotations   map[string]string   `json:"annotations"`
 	SecretNames   ldsc.SecretNames    `json:"-"`
 	SecretValues  ldsc.SecretValues   `json:"-"`
 }
 
 // InspectDir look for files with informed extensions.
 func (c *Context) InspectDir(ns string, dirPath string, exts []string) error {
+	var overlays []string
 	var err error
 
 	logger := c.logger.WithFields(log.Fields{"namespace": ns, "dir": dirPath, "exts": exts})
@@ -78,42 +139,214 @@ func (c *Context) InspectDir(ns string, dirPath string, exts []string) error {
 		}
 	}
 
+	// sub-directories containing a kustomization file are overlays
+	if overlays, err = filepath.Glob(path.Join(dirPath, "*", KustomizationFile)); err != nil {
+		return err
+	}
+	for _, overlay := range overlays {
+		logger.Infof("Inspecting overlay: '%s'", path.Dir(overlay))
+		if err = c.AddFile(ns, path.Dir(overlay)); err != nil {
+			return err
+		}
+	}
+
 	logger.Infof("Files: '%s'", formatSlice(c.GetNamespaceFilesMap()[ns]))
 	return nil
 }
 
-// AddFile as Landscaper release or Vault-Handler secret manifest. It will try to parse payload first
-// as a Landscaper file, and if on errors, it tries as a secret manifest.
+// AddFile as Landscaper release, Vault-Handler secret manifest, namespace policy manifest or plain
+// Kubernetes manifest. File kind is taken from naming convention configured on namespaces, or from
+// "kind" declared in the file. Otherwise, files having "apiVersion" are parsed as policy and then
+// as Kubernetes manifest, while other files are parsed as Landscaper release and then as secret
+// manifest. When all parsers fail, the error carries each parser message. A directory informed as
+// file is built as kustomize overlay.
 func (c *Context) AddFile(ns, file string) error {
-	var component *Component
-	var manifest *vh.Manifest
+	var objs []*unstructured.Unstructured
+	var kinds []string
+	var errs []string
 	var err error
 
 	logger := c.logger.WithFields(log.Fields{"namespace": ns, "file": file})
 	logger.Debugf("Adding file '%s' on namespace '%s'", file, ns)
 
-	// trying as a landscaper file first
-	if err = yaml.UnmarshalStrict(readFile(file), &component); err == nil {
-		logger.Debug("Landscaper release file")
-		c.Releases[ns] = append(c.Releases[ns], Release{
-			Namespace: ns, File: file, Component: component,
-		})
+	if isDir(file) {
+		if !isKustomization(file) {
+			return fmt.Errorf("directory '%s' does not contain '%s'", file, KustomizationFile)
+		}
+		logger.Debug("Building kustomize overlay...")
+		if objs, err = BuildKustomization(file); err != nil {
+			return err
+		}
+		logger.Debugf("Valid kustomize overlay, %d objects", len(objs))
+		c.Overlays[ns] = append(c.Overlays[ns], Overlay{Namespace: ns, Dir: file, Objects: objs})
 		return nil
 	}
-	logger.Debugf("Error on parsing file as Landscaper's: '%s'", err)
 
-	logger.Debug("Trying to handle file as a secret manifest...")
-	// trying as a secret manifest afterwards
-	if err = yaml.UnmarshalStrict(readFile(file), &manifest); err == nil {
-		logger.Debug("Valid Vault-Handler secrets manifest file!")
+	payload := readFile(file)
+	if kinds, err = c.fileKinds(file, payload); err != nil {
+		return fmt.Errorf("file '%s': %s", file, err)
+	}
+
+	for _, kind := range kinds {
+		logger.Debugf("Trying to handle file as '%s'...", kind)
+		if err = c.addFileAs(ns, file, kind, payload); err == nil {
+			logger.Debugf("Valid '%s' file!", kind)
+			return nil
+		}
+		logger.Debugf("Error on parsing file as '%s': '%s'", kind, err)
+		errs = append(errs, fmt.Sprintf("as %s: %s", kind, indent(err.Error())))
+	}
+
+	return fmt.Errorf("unable to parse file '%s':\n  %s", file, strings.Join(errs, "\n  "))
+}
+
+// fileKinds kinds a file is parsed as, in order. Naming convention takes precedence over kind
+// declared in the file.
+func (c *Context) fileKinds(file string, payload []byte) ([]string, error) {
+	var header fileHeader
+	var kinds []string
+
+	for kind := range c.FileKinds {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		if !stringSliceContains(FileKinds, kind) {
+			return nil, fmt.Errorf("unknown file kind '%s' on naming convention", kind)
+		}
+		for _, pattern := range c.FileKinds[kind] {
+			matched, err := filepath.Match(pattern, filepath.Base(file))
+			if err != nil {
+				return nil, err
+			}
+			if matched {
+				return []string{kind}, nil
+			}
+		}
+	}
+
+	// header of first document, errors are reported by parsers
+	_ = yaml.Unmarshal(payload, &header)
+	switch {
+	case header.APIVersion != "":
+		return []string{FileKindPolicy, FileKindManifest}, nil
+	case header.Kind == "":
+		return []string{FileKindRelease, FileKindSecret}, nil
+	case header.Kind == FileKindRelease || header.Kind == FileKindSecret:
+		return []string{header.Kind}, nil
+	default:
+		return nil, fmt.Errorf("unknown kind '%s', expected '%s' or '%s'",
+			header.Kind, FileKindRelease, FileKindSecret)
+	}
+}
+
+// addFileAs parse payload as informed kind, and add it to context.
+func (c *Context) addFileAs(ns, file, kind string, payload []byte) error {
+	var component *Component
+	var secret *secretFile
+	var obj *unstructured.Unstructured
+	var objs []*unstructured.Unstructured
+	var err error
+
+	switch kind {
+	case FileKindRelease:
+		if err = yaml.UnmarshalStrict(payload, &component); err != nil {
+			return err
+		}
+		// a release must be present, since "secrets" is a valid key for both formats
+		if component == nil || component.Release == nil {
+			return fmt.Errorf("release is not defined")
+		}
+		c.Releases[ns] = append(c.Releases[ns], Release{
+			Namespace: ns, File: file, Component: component,
+		})
+	case FileKindSecret:
+		if err = yaml.UnmarshalStrict(payload, &secret); err != nil {
+			return err
+		}
+		if secret == nil {
+			return fmt.Errorf("secrets are not defined")
+		}
 		c.Secrets[ns] = append(c.Secrets[ns], SecretManifest{
-			Namespace: ns, File: file, Manifest: manifest,
+			Namespace: ns, File: file, Manifest: &secret.Manifest,
 		})
-		return nil
+	case FileKindPolicy:
+		if obj, err = parsePolicy(payload); err != nil {
+			return err
+		}
+		c.Policies[ns] = append(c.Policies[ns], PolicyManifest{
+			Namespace: ns, File: file, Object: obj,
+		})
+	case FileKindManifest:
+		if objs, err = parseManifest(payload); err != nil {
+			return err
+		}
+		c.Manifests[ns] = append(c.Manifests[ns], Manifest{
+			Namespace: ns, File: file, Objects: objs,
+		})
+	default:
+		return fmt.Errorf("unknown file kind '%s'", kind)
+	}
+	return nil
+}
+
+// indent multi-line parser messages, to be nested on file error.
+func indent(message string) string {
+	return strings.Replace(message, "\n", "\n    ", -1)
+}
+
+// parsePolicy parse payload as a single Kubernetes object, of a supported namespace policy kind.
+func parsePolicy(payload []byte) (*unstructured.Unstructured, error) {
+	var objs []*unstructured.Unstructured
+	var err error
+
+	if objs, err = parseManifest(payload); err != nil {
+		return nil, err
+	}
+	if len(objs) > 1 {
+		return nil, fmt.Errorf("policy file must hold a single object, found %d", len(objs))
+	}
+	obj := objs[0]
+	if _, found := policyResources[obj.GetKind()]; !found {
+		return nil, fmt.Errorf("kind '%s' is not a supported namespace policy", obj.GetKind())
+	}
+	return obj, nil
+}
+
+// parseManifest parse payload as one or more Kubernetes objects, YAML documents without content
+// are ignored.
+func parseManifest(payload []byte) ([]*unstructured.Unstructured, error) {
+	var objs []*unstructured.Unstructured
+
+	decoder := k8syaml.NewYAMLOrJSONDecoder(bytes.NewReader(payload), 4096)
+	for doc := 1; ; doc++ {
+		var raw json.RawMessage
+
+		if err := decoder.Decode(&raw); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("document %d: %s", doc, err)
+		}
+		if len(raw) == 0 || string(raw) == "null" {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(raw); err != nil {
+			return nil, fmt.Errorf("document %d: %s", doc, err)
+		}
+		if obj.GetAPIVersion() == "" || obj.GetKind() == "" || obj.GetName() == "" {
+			return nil, fmt.Errorf("document %d: object must have apiVersion, kind and metadata.name",
+				doc)
+		}
+		objs = append(objs, obj)
 	}
-	logger.Debugf("Error on parsing file as Vault-Handler's: '%s'", err)
 
-	return fmt.Errorf("unable to parse file '%s' as landscaper release or vault-handler manifest", file)
+	if len(objs) == 0 {
+		return nil, fmt.Errorf("no kubernetes objects found")
+	}
+	return objs, nil
 }
 
 // RenameReleases based on prefix and suffix, rename the existing releases.
@@ -132,10 +365,13 @@ func (c *Context) RenameReleases(fn ReleaseRenamer) error {
 }
 
 // RenameNamespaces loop namespaces in this context to rename it based in informed method output,
-// applied to releases and secrets in this context.
+// applied to releases, secrets, policies, manifests and overlays in this context.
 func (c *Context) RenameNamespaces(fn NamespaceRenamer) {
 	var r = make(map[string][]Release)
 	var s = make(map[string][]SecretManifest)
+	var p = make(map[string][]PolicyManifest)
+	var m = make(map[string][]Manifest)
+	var o = make(map[string][]Overlay)
 
 	for k, v := range c.Releases {
 		r[fn(k)] = v
@@ -146,6 +382,21 @@ func (c *Context) RenameNamespaces(fn NamespaceRenamer) {
 		s[fn(k)] = v
 	}
 	c.Secrets = s
+
+	for k, v := range c.Policies {
+		p[fn(k)] = v
+	}
+	c.Policies = p
+
+	for k, v := range c.Manifests {
+		m[fn(k)] = v
+	}
+	c.Manifests = m
+
+	for k, v := range c.Overlays {
+		o[fn(k)] = v
+	}
+	c.Overlays = o
 }
 
 // GetNamespaceFilesMap expose map of namespace and its files
@@ -162,6 +413,21 @@ func (c *Context) GetNamespaceFilesMap() map[string][]string {
 			filesMap[ns] = append(filesMap[ns], secret.File)
 		}
 	}
+	for ns, policies := range c.Policies {
+		for _, policy := range policies {
+			filesMap[ns] = append(filesMap[ns], policy.File)
+		}
+	}
+	for ns, manifests := range c.Manifests {
+		for _, manifest := range manifests {
+			filesMap[ns] = append(filesMap[ns], manifest.File)
+		}
+	}
+	for ns, overlays := range c.Overlays {
+		for _, overlay := range overlays {
+			filesMap[ns] = append(filesMap[ns], overlay.Dir)
+		}
+	}
 
 	return filesMap
 }
@@ -169,8 +435,11 @@ func (c *Context) GetNamespaceFilesMap() map[string][]string {
 // NewContext creates a empty new context instance.
 func NewContext() *Context {
 	return &Context{
-		logger:   log.WithField("type", "context"),
-		Releases: make(map[string][]Release),
-		Secrets:  make(map[string][]SecretManifest),
+		logger:    log.WithField("type", "context"),
+		Releases:  make(map[string][]Release),
+		Secrets:   make(map[string][]SecretManifest),
+		Policies:  make(map[string][]PolicyManifest),
+		Manifests: make(map[string][]Manifest),
+		Overlays:  make(map[string][]Overlay),
 	}
 }
@@ -2,6 +2,8 @@ package galaxy
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 	"path"
 	"testing"
 
@@ -14,8 +16,9 @@ func populatedContext(t *testing.T) *Context {
 	ctx := NewContext()
 	dotGalaxy, err := NewDotGalaxy("../../test/galaxy.yaml")
 	assert.Nil(t, err)
+	ctx.FileKinds = dotGalaxy.Spec.Namespaces.FileKinds
 
-	for _, ns := range dotGalaxy.Spec.Namespaces.Names {
+	for _, ns := range dotGalaxy.ListNamespaces() {
 		dirPath := path.Join(dotGalaxy.Spec.Namespaces.BaseDir, ns)
 		err = ctx.InspectDir(ns, dirPath, dotGalaxy.Spec.Namespaces.Extensions)
 		assert.Nil(t, err)
@@ -35,9 +38,15 @@ func TestContextInspectDir(t *testing.T) {
 		t.Logf("secret: '%#v'", secret)
 	}
 
-	assert.Equal(t, 3, len(ctx.Releases["ns1"]))
+	assert.Equal(t, 4, len(ctx.Releases["ns1"]))
 	assert.Equal(t, 1, len(ctx.Secrets["ns1"]))
 	assert.Equal(t, 1, len(ctx.Releases["ns2"]))
+	assert.Equal(t, 1, len(ctx.Policies["ns2"]))
+	assert.Equal(t, "ResourceQuota", ctx.Policies["ns2"][0].Object.GetKind())
+	assert.Equal(t, 1, len(ctx.Manifests["ns2"]))
+	assert.Equal(t, 2, len(ctx.Manifests["ns2"][0].Objects))
+	assert.Equal(t, 1, len(ctx.Overlays["ns3"]))
+	assert.Equal(t, 2, len(ctx.Overlays["ns3"][0].Objects))
 }
 
 func TestContextRenameReleases(t *testing.T) {
@@ -69,4 +78,98 @@ func TestContextRenameNamespaces(t *testing.T) {
 	for ns = range ctx.Secrets {
 		assert.Contains(t, ns, "test-")
 	}
+	for ns = range ctx.Policies {
+		assert.Contains(t, ns, "test-")
+	}
+	for ns = range ctx.Manifests {
+		assert.Contains(t, ns, "test-")
+	}
+	for ns = range ctx.Overlays {
+		assert.Contains(t, ns, "test-")
+	}
+}
+
+func TestContextParsePolicy(t *testing.T) {
+	_, err := parsePolicy([]byte("kind: Deployment\nmetadata:\n  name: app\n"))
+	assert.NotNil(t, err)
+
+	_, err = parsePolicy([]byte("kind: LimitRange\n"))
+	assert.NotNil(t, err)
+
+	obj, err := parsePolicy([]byte("apiVersion: v1\nkind: LimitRange\nmetadata:\n  name: limits\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, "limits", obj.GetName())
+
+	_, err = parsePolicy([]byte("apiVersion: v1\nkind: LimitRange\nmetadata:\n  name: a\n---\n" +
+		"apiVersion: v1\nkind: LimitRange\nmetadata:\n  name: b\n"))
+	assert.NotNil(t, err)
+}
+
+func TestContextParseManifest(t *testing.T) {
+	_, err := parseManifest([]byte("---\n---\n"))
+	assert.NotNil(t, err)
+
+	_, err = parseManifest([]byte("apiVersion: v1\nkind: ConfigMap\n"))
+	assert.NotNil(t, err)
+
+	objs, err := parseManifest([]byte(`---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+---
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+`))
+	assert.Nil(t, err)
+	assert.Len(t, objs, 2)
+	assert.Equal(t, "b", objs[1].GetName())
+}
+
+func TestContextAddFileKinds(t *testing.T) {
+	dir, err := ioutil.TempDir("", "galaxy-context")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	write := func(name, payload string) string {
+		file := path.Join(dir, name)
+		assert.Nil(t, ioutil.WriteFile(file, []byte(payload), 0600))
+		return file
+	}
+
+	ctx := NewContext()
+
+	// both parser messages are reported, with line numbers
+	file := write("typo.yaml", "name: app\nrelase:\n  chart: stable/app\n")
+	err = ctx.AddFile("ns1", file)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "as release")
+	assert.Contains(t, err.Error(), "as secret")
+	assert.Contains(t, err.Error(), "line 2")
+
+	// declared kind narrows down parsers
+	file = write("declared.yaml", "kind: release\nname: app\nrelase:\n  chart: stable/app\n")
+	err = ctx.AddFile("ns1", file)
+	assert.NotNil(t, err)
+	assert.NotContains(t, err.Error(), "as secret")
+
+	file = write("unknown.yaml", "kind: chart\nname: app\n")
+	assert.NotNil(t, ctx.AddFile("ns1", file))
+
+	file = write("release.yaml", "kind: release\nname: app\nrelease:\n  chart: stable/app\n"+
+		"  version: 0.0.1\n")
+	assert.Nil(t, ctx.AddFile("ns1", file))
+	assert.Len(t, ctx.Releases["ns1"], 1)
+
+	// naming convention takes precedence
+	ctx.FileKinds = map[string][]string{FileKindSecret: {"*-secret.yaml"}}
+	file = write("app-secret.yaml", "kind: release\nsecrets: {}\n")
+	assert.Nil(t, ctx.AddFile("ns1", file))
+	assert.Len(t, ctx.Secrets["ns1"], 1)
+
+	ctx.FileKinds = map[string][]string{"chart": {"*.yaml"}}
+	assert.NotNil(t, ctx.AddFile("ns1", file))
 }
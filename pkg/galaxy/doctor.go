@@ -0,0 +1,519 @@
+package galaxy
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	vaultapi "github.com/hashicorp/vault/api"
+	"github.com/ryanuber/columnize"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/helm/helmpath"
+	"k8s.io/helm/pkg/repo"
+	authorization "k8s.io/kubernetes/pkg/apis/authorization"
+)
+
+const (
+	// CheckPass check has passed
+	CheckPass = "pass"
+	// CheckFail check has failed
+	CheckFail = "fail"
+	// CheckSkip check is not applicable, or depends on a failed check
+	CheckSkip = "skip"
+)
+
+// accessRule resource and verbs required on target namespaces, or cluster wide.
+type accessRule struct {
+	group    string   // api group
+	resource string   // resource name
+	verbs    []string // required verbs
+	cluster  bool     // cluster scoped resource, checked once
+}
+
+// secretsAccess secrets are created by Galaxy and Landscaper.
+var secretsAccess = accessRule{
+	group: "", resource: "secrets", verbs: []string{"get", "list", "create", "update", "delete"},
+}
+
+// workloadsAccess workloads are patched by restarter, when secrets are handled.
+var workloadsAccess = []accessRule{
+	{group: "extensions", resource: "deployments", verbs: []string{"get", "list", "patch"}},
+	{group: "apps", resource: "statefulsets", verbs: []string{"get", "list", "patch"}},
+}
+
+// Check result of a single pre-flight check.
+type Check struct {
+	Name    string // check name
+	Target  string // what has been checked, context, namespace, chart, etc
+	Status  string // pass, fail or skip
+	Message string // details
+}
+
+// Doctor inspects connectivity and permissions required to apply an environment, recording the
+// outcome of each check instead of stopping on first failure.
+type Doctor struct {
+	logger     *log.Entry       // logger
+	cfg        *Config          // runtime configuration
+	clients    *Clients         // kubernetes and helm clients cache
+	env        *Environment     // environment instance
+	ctxs       []*Context       // slice of context instances
+	namespaces []string         // target namespaces
+	nsSpecs    []*NamespaceSpec // dot-galaxy entries of target namespaces
+	charts     *LocalCharts     // local chart directories
+	Checks     []Check          // checks results
+}
+
+// record check result.
+func (d *Doctor) record(name, target, status, message string) {
+	d.logger.WithFields(log.Fields{"check": name, "target": target, "status": status}).Info(message)
+	d.Checks = append(d.Checks, Check{Name: name, Target: target, Status: status, Message: message})
+}
+
+// recordErr record check as passed when error is nil, failed otherwise.
+func (d *Doctor) recordErr(name, target, message string, err error) bool {
+	if err != nil {
+		d.record(name, target, CheckFail, err.Error())
+		return false
+	}
+	d.record(name, target, CheckPass, message)
+	return true
+}
+
+// Run all checks, returns error when any of them failed.
+func (d *Doctor) Run() error {
+	d.Checks = []Check{}
+
+	if kubeClient := d.checkKubernetes(); kubeClient != nil {
+		d.checkAccess(kubeClient)
+		d.checkTiller(kubeClient)
+	}
+	d.checkSecretSource()
+	d.checkCharts()
+
+	if failed := ChecksFailed(d.Checks); failed > 0 {
+		return fmt.Errorf("%d pre-flight check(s) failed", failed)
+	}
+	return nil
+}
+
+// checkKubernetes kube-config and context, and API server reachability.
+func (d *Doctor) checkKubernetes() *KubeClient {
+	target := d.cfg.KubeContext
+	if target == "" {
+		target = "<current>"
+	}
+
+	kubeClient, err := d.clients.Kube(d.cfg.KubeContext)
+	if !d.recordErr("kube-config", target, "Kubernetes client configured", err) {
+		d.record("kube-api", target, CheckSkip, "Kubernetes client is not configured")
+		return nil
+	}
+
+	version, err := kubeClient.Client.Discovery().ServerVersion()
+	if err != nil {
+		d.record("kube-api", kubeClient.RestCfg.Host, CheckFail, err.Error())
+		return nil
+	}
+	d.record("kube-api", kubeClient.RestCfg.Host, CheckPass,
+		fmt.Sprintf("API server version '%s'", version.GitVersion))
+	return kubeClient
+}
+
+// accessRules resources and verbs required to apply the planned environment: namespaces, cluster
+// wide, secrets and workloads, plus policy and manifest kinds planned on target namespaces. Verbs
+// employed to prune are only required when pruning is enabled.
+func (d *Doctor) accessRules(kubeClient *KubeClient) ([]accessRule, error) {
+	var mapper meta.RESTMapper
+
+	nsVerbs := []string{"get"}
+	for _, spec := range d.nsSpecs {
+		if spec.Create && !stringSliceContains(nsVerbs, "create") {
+			nsVerbs = append(nsVerbs, "create")
+		}
+		if (len(spec.Labels) > 0 || len(spec.Annotations) > 0) &&
+			!stringSliceContains(nsVerbs, "update") {
+			nsVerbs = append(nsVerbs, "update")
+		}
+	}
+	rules := []accessRule{
+		{group: "", resource: "namespaces", verbs: nsVerbs, cluster: true},
+		secretsAccess,
+	}
+	// workloads are only patched when secrets are handled
+	if !d.cfg.SkipSecrets {
+		rules = append(rules, workloadsAccess...)
+	}
+
+	policyVerbs := []string{"get", "create", "update"}
+	if d.cfg.PrunePolicies {
+		policyVerbs = append(policyVerbs, "list", "delete")
+	}
+	manifestVerbs := []string{"get", "create", "patch"}
+	if d.cfg.PruneManifests {
+		manifestVerbs = append(manifestVerbs, "list", "delete")
+	}
+
+	var kinds []schema.GroupKind
+	for _, ctx := range d.ctxs {
+		for _, ns := range d.namespaces {
+			for _, policy := range ctx.Policies[ns] {
+				gvr := policyResources[policy.Object.GetKind()]
+				rules = appendAccessRule(rules, accessRule{
+					group: gvr.Group, resource: gvr.Resource, verbs: policyVerbs,
+				})
+			}
+			for _, o := range namespaceObjects(ctx, ns) {
+				gk := o.obj.GroupVersionKind().GroupKind()
+				if !groupKindSliceContains(kinds, gk) {
+					kinds = append(kinds, gk)
+				}
+			}
+		}
+	}
+	if len(kinds) == 0 {
+		return rules, nil
+	}
+
+	groupResources, err := restmapper.GetAPIGroupResources(kubeClient.Client.Discovery())
+	if err != nil {
+		return nil, err
+	}
+	mapper = restmapper.NewDiscoveryRESTMapper(groupResources)
+	for _, gk := range kinds {
+		mapping, err := mapper.RESTMapping(gk)
+		if err != nil {
+			return nil, err
+		}
+		if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+			return nil, fmt.Errorf("cluster scoped kind '%s' is not supported", gk.Kind)
+		}
+		rules = appendAccessRule(rules, accessRule{
+			group: mapping.Resource.Group, resource: mapping.Resource.Resource, verbs: manifestVerbs,
+		})
+	}
+	return rules, nil
+}
+
+// appendAccessRule append rule when resource is not present yet.
+func appendAccessRule(rules []accessRule, rule accessRule) []accessRule {
+	for _, existing := range rules {
+		if existing.group == rule.group && existing.resource == rule.resource {
+			return rules
+		}
+	}
+	return append(rules, rule)
+}
+
+// checkAccess inspect RBAC permissions on each target namespace, and cluster wide, via
+// SelfSubjectAccessReview. On dry-run only read verbs are required.
+func (d *Doctor) checkAccess(kubeClient *KubeClient) {
+	rules, err := d.accessRules(kubeClient)
+	if err != nil {
+		d.record("rbac", "<planned kinds>", CheckFail, err.Error())
+		return
+	}
+
+	for _, rule := range rules {
+		if rule.cluster {
+			d.checkRule(kubeClient, "", rule)
+			continue
+		}
+		for _, ns := range d.namespaces {
+			d.checkRule(kubeClient, ns, rule)
+		}
+	}
+}
+
+// checkRule inspect verbs of a single rule on namespace, empty for cluster scoped resources.
+func (d *Doctor) checkRule(kubeClient *KubeClient, ns string, rule accessRule) {
+	var denied []string
+	var err error
+
+	reviews := kubeClient.Client.Authorization().SelfSubjectAccessReviews()
+	for _, verb := range rule.verbs {
+		var review *authorization.SelfSubjectAccessReview
+
+		if d.cfg.DryRun && verb != "get" && verb != "list" {
+			continue
+		}
+
+		review, err = reviews.Create(&authorization.SelfSubjectAccessReview{
+			Spec: authorization.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorization.ResourceAttributes{
+					Namespace: ns,
+					Verb:      verb,
+					Group:     rule.group,
+					Resource:  rule.resource,
+				},
+			},
+		})
+		if err != nil {
+			break
+		}
+		if !review.Status.Allowed {
+			denied = append(denied, verb)
+		}
+	}
+
+	target := fmt.Sprintf("%s/%s", ns, rule.resource)
+	if rule.cluster {
+		target = rule.resource
+	}
+	switch {
+	case err != nil:
+		d.record("rbac", target, CheckFail, err.Error())
+	case len(denied) > 0:
+		d.record("rbac", target, CheckFail,
+			fmt.Sprintf("verbs not allowed: %s", strings.Join(denied, ", ")))
+	default:
+		d.record("rbac", target, CheckPass, "required verbs are allowed")
+	}
+}
+
+// checkTiller inspect Tiller pod readiness, and version compatibility using Helm client.
+func (d *Doctor) checkTiller(kubeClient *KubeClient) {
+	var helmClient *HelmClient
+	var podName string
+	var err error
+
+	target := d.cfg.TillerNamespace
+	if hostname := os.Getenv("HELM_HOST"); hostname != "" {
+		d.record("tiller-pod", hostname, CheckSkip, "using HELM_HOST to reach Tiller")
+	} else {
+		h := NewHelmClient(d.cfg.HelmHome, d.cfg.TillerNamespace, d.cfg.TillerPort,
+			d.cfg.TillerTimeout, nil, kubeClient)
+		if podName, err = h.getHelmTillerPodName(); err != nil {
+			d.record("tiller-pod", target, CheckFail, err.Error())
+			d.record("tiller", target, CheckSkip, "Tiller pod is not ready")
+			return
+		}
+		d.record("tiller-pod", target, CheckPass, fmt.Sprintf("pod '%s' is ready", podName))
+	}
+
+	tlsCfg := d.env.Tiller.Merge(d.cfg.TillerTLSConfig).WithDefaults(d.cfg.HelmHome)
+	if helmClient, err = d.clients.Helm(d.cfg.KubeContext, tlsCfg); err != nil {
+		d.record("tiller", target, CheckFail, err.Error())
+		return
+	}
+	version, err := helmClient.Client.GetVersion()
+	if err != nil {
+		d.record("tiller", target, CheckFail, err.Error())
+		return
+	}
+	d.record("tiller", target, CheckPass,
+		fmt.Sprintf("Tiller version '%s' is compatible", version.Version.SemVer))
+}
+
+// checkSecretSource inspect secret source configuration, and for Vault, reachability and
+// authentication, using the shared Vault client.
+func (d *Doctor) checkSecretSource() {
+	var health *vaultapi.HealthResponse
+	var err error
+
+	if d.cfg.SkipSecrets {
+		d.record("secrets", d.env.Secrets.GetSource(), CheckSkip, "secrets are skipped")
+		return
+	}
+	if err = d.env.Secrets.Validate(); err != nil {
+		d.record("secrets", d.env.Secrets.GetSource(), CheckFail, err.Error())
+		return
+	}
+	if d.env.Secrets.GetSource() == SecretSourceFile {
+		d.checkSecretFiles()
+		return
+	}
+
+	target := d.cfg.VaultAddr
+	vaultClient := NewVaultClient(d.cfg.VaultHandlerConfig, d.cfg.KubernetesConfig)
+	if err = vaultClient.Load(); err == nil {
+		health, err = vaultClient.Client.Sys().Health()
+	}
+	if err == nil && health.Sealed {
+		err = fmt.Errorf("vault is sealed")
+	}
+	if err != nil {
+		d.record("vault", target, CheckFail, err.Error())
+		d.record("vault-auth", target, CheckSkip, "Vault is not reachable")
+		return
+	}
+	d.record("vault", target, CheckPass, fmt.Sprintf("Vault version '%s'", health.Version))
+
+	// session is kept by shared clients, and reused when applying secrets
+	_, err = d.clients.Vault()
+	d.recordErr("vault-auth", target, "authenticated on Vault", err)
+}
+
+// checkSecretFiles inspect if secrets planned on target namespaces are readable from local files,
+// decrypting them with informed passphrase when needed. Each unreadable secret is reported.
+func (d *Doctor) checkSecretFiles() {
+	var err error
+
+	source := NewFileSecretSource(d.env.Secrets.Dir, d.env.Secrets.KeyRing, d.cfg.SecretsPassphrase)
+	read, failed := 0, 0
+	for _, ctx := range d.ctxs {
+		for _, ns := range d.namespaces {
+			for _, secret := range ctx.Secrets[ns] {
+				var names []string
+
+				for name := range secret.Manifest.Secrets {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+
+				for _, name := range names {
+					if _, err = source.Read(secret.Manifest.Secrets[name].Path); err != nil {
+						d.record("secrets", fmt.Sprintf("%s/%s", ns, name), CheckFail, err.Error())
+						failed++
+						continue
+					}
+					read++
+				}
+			}
+		}
+	}
+	if failed > 0 {
+		return
+	}
+	d.record("secrets", d.env.Secrets.Dir, CheckPass,
+		fmt.Sprintf("%d secret(s) are readable from secrets directory", read))
+}
+
+// checkCharts inspect if charts employed by releases on target namespaces are found on local
+// repositories index, in Helm home. Local chart directories are loaded instead.
+func (d *Doctor) checkCharts() {
+	var remoteRefs []string
+
+	for _, chartRef := range d.chartRefs() {
+		if !isLocalChartRef(chartRef) {
+			remoteRefs = append(remoteRefs, chartRef)
+			continue
+		}
+		d.checkLocalChart(chartRef)
+	}
+	if len(remoteRefs) == 0 {
+		return
+	}
+
+	home := helmpath.Home(d.cfg.HelmHome)
+	repoFile, err := repo.LoadRepositoriesFile(home.RepositoryFile())
+	if err != nil {
+		d.record("charts", home.RepositoryFile(), CheckFail, err.Error())
+		return
+	}
+
+	indexes := make(map[string]*repo.IndexFile)
+	for _, chartRef := range remoteRefs {
+		var index *repo.IndexFile
+		var found bool
+
+		name, version := splitChartRef(chartRef)
+		parts := strings.Split(name, "/")
+		if len(parts) != 2 {
+			d.record("chart", chartRef, CheckFail, "expected chart as 'repo/name'")
+			continue
+		}
+		repoName, chartName := parts[0], parts[1]
+
+		if !repoFile.Has(repoName) {
+			d.record("chart", chartRef, CheckFail,
+				fmt.Sprintf("repository '%s' is not configured in Helm home", repoName))
+			continue
+		}
+		if index, found = indexes[repoName]; !found {
+			if index, err = repo.LoadIndexFile(home.CacheIndex(repoName)); err != nil {
+				d.record("chart", chartRef, CheckFail, err.Error())
+				continue
+			}
+			indexes[repoName] = index
+		}
+		if _, err = index.Get(chartName, version); err != nil {
+			d.record("chart", chartRef, CheckFail, err.Error())
+			continue
+		}
+		d.record("chart", chartRef, CheckPass, "found on repository index")
+	}
+}
+
+// checkLocalChart inspect if local chart directory is loadable, and matches the version informed.
+func (d *Doctor) checkLocalChart(chartRef string) {
+	name, version := splitChartRef(chartRef)
+	dir := d.charts.ChartDir(name)
+
+	ch, err := chartutil.LoadDir(dir)
+	if err != nil {
+		d.record("chart", chartRef, CheckFail, err.Error())
+		return
+	}
+	if version != "" && version != ch.Metadata.Version {
+		d.record("chart", chartRef, CheckFail,
+			fmt.Sprintf("local chart has version '%s'", ch.Metadata.Version))
+		return
+	}
+	d.record("chart", chartRef, CheckPass, fmt.Sprintf("local chart found at '%s'", dir))
+}
+
+// chartRefs unique chart references employed by releases on target namespaces, sorted.
+func (d *Doctor) chartRefs() []string {
+	var chartRefs []string
+
+	for _, ctx := range d.ctxs {
+		for _, ns := range d.namespaces {
+			for _, release := range ctx.Releases[ns] {
+				if !stringSliceContains(chartRefs, release.Component.Release.Chart) {
+					chartRefs = append(chartRefs, release.Component.Release.Chart)
+				}
+			}
+		}
+	}
+	sort.Strings(chartRefs)
+	return chartRefs
+}
+
+// ChecksFailed amount of failed checks.
+func ChecksFailed(checks []Check) int {
+	failed := 0
+	for _, check := range checks {
+		if check.Status == CheckFail {
+			failed++
+		}
+	}
+	return failed
+}
+
+// ChecksTable format checks as a table.
+func ChecksTable(checks []Check) string {
+	lines := []string{"CHECK | TARGET | STATUS | MESSAGE"}
+	for _, check := range checks {
+		lines = append(lines, fmt.Sprintf("%s | %s | %s | %s",
+			check.Name, check.Target, strings.ToUpper(check.Status), check.Message))
+	}
+	return columnize.SimpleFormat(lines)
+}
+
+// NewDoctor instantiate pre-flight checks for environment and target namespaces, with their
+// dot-galaxy entries. Local charts are relative to base directory.
+func NewDoctor(
+	cfg *Config,
+	clients *Clients,
+	env *Environment,
+	ctxs []*Context,
+	namespaces []string,
+	nsSpecs []*NamespaceSpec,
+	baseDir string,
+) *Doctor {
+	return &Doctor{
+		logger:     log.WithFields(log.Fields{"type": "doctor", "env": env.Name}),
+		cfg:        cfg,
+		clients:    clients,
+		env:        env,
+		ctxs:       ctxs,
+		namespaces: namespaces,
+		nsSpecs:    nsSpecs,
+		charts:     NewLocalCharts(baseDir, cfg.HelmHome),
+	}
+}
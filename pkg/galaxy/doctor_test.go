@@ -0,0 +1,165 @@
+package galaxy
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	vh "github.com/otaviof/vault-handler/pkg/vault-handler"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/openpgp"
+)
+
+// fakeHelmHome creates a Helm home with "stable" repository, and an index containing grafana.
+func fakeHelmHome(t *testing.T) string {
+	home, err := ioutil.TempDir("", "galaxy-helm-home")
+	assert.Nil(t, err)
+
+	cacheDir := filepath.Join(home, "repository", "cache")
+	assert.Nil(t, os.MkdirAll(cacheDir, 0700))
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(home, "repository", "repositories.yaml"), []byte(`---
+apiVersion: v1
+repositories:
+  - name: stable
+    url: https://kubernetes-charts.storage.googleapis.com
+    cache: `+filepath.Join(cacheDir, "stable-index.yaml")+`
+`), 0600))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(cacheDir, "stable-index.yaml"), []byte(`---
+apiVersion: v1
+entries:
+  grafana:
+    - name: grafana
+      version: 3.3.0
+      urls:
+        - https://kubernetes-charts.storage.googleapis.com/grafana-3.3.0.tgz
+`), 0600))
+	return home
+}
+
+func TestDoctorCheckCharts(t *testing.T) {
+	home := fakeHelmHome(t)
+	defer os.RemoveAll(home)
+
+	ctx := NewContext()
+	err := ctx.AddFile("ns1", "../../test/namespaces/ns1/app1.yaml")
+	assert.Nil(t, err)
+
+	cfg := NewConfig()
+	cfg.HelmHome = home
+	d := NewDoctor(cfg, nil, &Environment{Name: "dev"}, []*Context{ctx}, []string{"ns1"}, nil, "")
+
+	d.checkCharts()
+	assert.Len(t, d.Checks, 1)
+	assert.Equal(t, CheckPass, d.Checks[0].Status)
+
+	ctx.Releases["ns1"][0].Component.Release.Chart = "stable/grafana:9.9.9"
+	d.Checks = []Check{}
+	d.checkCharts()
+	assert.Equal(t, CheckFail, d.Checks[0].Status)
+
+	ctx.Releases["ns1"][0].Component.Release.Chart = "other/grafana"
+	d.Checks = []Check{}
+	d.checkCharts()
+	assert.Equal(t, CheckFail, d.Checks[0].Status)
+	assert.Contains(t, d.Checks[0].Message, "other")
+}
+
+func TestDoctorRun(t *testing.T) {
+	kubeConfig := fakeKubeConfig(t)
+	defer os.Remove(kubeConfig)
+	home := fakeHelmHome(t)
+	defer os.RemoveAll(home)
+
+	cfg := NewConfig()
+	cfg.KubeConfig = kubeConfig
+	cfg.KubeContext = "b"
+	cfg.HelmHome = home
+	cfg.SkipSecrets = true
+	d := NewDoctor(cfg, NewClients(cfg.KubernetesConfig, cfg.LandscaperConfig, cfg.VaultHandlerConfig),
+		&Environment{Name: "dev"}, []*Context{}, []string{"ns1"}, nil, "")
+
+	err := d.Run()
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, ChecksFailed(d.Checks))
+
+	statuses := map[string]string{}
+	for _, check := range d.Checks {
+		statuses[check.Name] = check.Status
+	}
+	assert.Equal(t, CheckPass, statuses["kube-config"])
+	assert.Equal(t, CheckFail, statuses["kube-api"])
+	assert.Equal(t, CheckSkip, statuses["secrets"])
+
+	t.Logf("Checks:\n%s", ChecksTable(d.Checks))
+}
+
+func TestDoctorCheckSecretFiles(t *testing.T) {
+	dir, secretDir := fakeSecretsDir(t)
+	defer os.RemoveAll(dir)
+
+	writeEncrypted(t, filepath.Join(secretDir, "tls.crt.gpg"), []byte("certificate"),
+		func(buf io.Writer) io.WriteCloser {
+			w, err := openpgp.SymmetricallyEncrypt(buf, []byte(fileSecretSourcePassphrase), nil, nil)
+			assert.Nil(t, err)
+			return w
+		})
+
+	ctx := NewContext()
+	assert.Nil(t, ctx.AddFile("ns1-d", "../../test/namespaces/ns1/ingress-secret.yaml"))
+	env := &Environment{Name: "dev", Secrets: SecretsSpec{Source: SecretSourceFile, Dir: dir}}
+
+	secrets := ctx.Secrets["ns1-d"][0].Manifest.Secrets
+	secrets["missing"] = vh.Secrets{
+		Path: "secret/data/missing", Data: []vh.SecretData{{Name: "key", Extension: "secret"}},
+	}
+
+	// every unreadable secret is reported
+	cfg := NewConfig()
+	d := NewDoctor(cfg, nil, env, []*Context{ctx}, []string{"ns1-d"}, nil, "")
+	d.checkSecretSource()
+	assert.Len(t, d.Checks, 2)
+	assert.Equal(t, 2, ChecksFailed(d.Checks))
+	assert.Equal(t, "ns1-d/ingress", d.Checks[0].Target)
+	assert.Equal(t, "ns1-d/missing", d.Checks[1].Target)
+
+	cfg.SecretsPassphrase = fileSecretSourcePassphrase
+	d.Checks = []Check{}
+	d.checkSecretSource()
+	assert.Len(t, d.Checks, 1)
+	assert.Equal(t, "ns1-d/missing", d.Checks[0].Target)
+
+	delete(secrets, "missing")
+	d.Checks = []Check{}
+	d.checkSecretSource()
+	assert.Equal(t, CheckPass, d.Checks[0].Status)
+}
+
+func TestDoctorAccessRules(t *testing.T) {
+	ctx := NewContext()
+	assert.Nil(t, ctx.AddFile("ns2-d", "../../test/namespaces/ns2/quota.yaml"))
+
+	cfg := NewConfig()
+	cfg.SkipSecrets = true
+	specs := []*NamespaceSpec{{Name: "ns2", Create: true, Labels: map[string]string{"a": "b"}}}
+	d := NewDoctor(cfg, nil, &Environment{Name: "dev"}, []*Context{ctx}, []string{"ns2-d"}, specs, "")
+
+	rules, err := d.accessRules(nil)
+	assert.Nil(t, err)
+	assert.Len(t, rules, 3)
+	assert.Equal(t, "namespaces", rules[0].resource)
+	assert.True(t, rules[0].cluster)
+	assert.Equal(t, []string{"get", "create", "update"}, rules[0].verbs)
+	assert.Equal(t, "secrets", rules[1].resource)
+	assert.Equal(t, "resourcequotas", rules[2].resource)
+	assert.Equal(t, []string{"get", "create", "update"}, rules[2].verbs)
+
+	cfg.SkipSecrets = false
+	cfg.PrunePolicies = true
+	rules, err = d.accessRules(nil)
+	assert.Nil(t, err)
+	assert.Len(t, rules, 5)
+	assert.Equal(t, []string{"get", "create", "update", "list", "delete"}, rules[4].verbs)
+}
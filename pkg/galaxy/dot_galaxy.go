@@ -2,10 +2,13 @@ package galaxy
 
 import (
 	"fmt"
+	"os"
 	"path"
 
 	"github.com/buildkite/interpolate"
 	yaml "gopkg.in/yaml.v2"
+	"k8s.io/helm/pkg/helm/helmpath"
+	"k8s.io/helm/pkg/repo"
 )
 
 // DotGalaxy represents the `.galaxy.yaml` configuration file
@@ -17,15 +20,49 @@ type DotGalaxy struct {
 type Spec struct {
 	Environments []Environment `yaml:"environments"`
 	Namespaces   Namespaces    `yaml:"namespaces"`
+	Charts       ChartsSpec    `yaml:"charts"`
+}
+
+// ChartsSpec chart repositories employed by releases
+type ChartsSpec struct {
+	Repositories []ChartRepositorySpec `yaml:"repositories"`
+}
+
+// ChartRepositorySpec chart repository, credentials are expanded from environment variables
+type ChartRepositorySpec struct {
+	Name     string `yaml:"name"`     // repository name, as in chart reference
+	URL      string `yaml:"url"`      // repository url
+	Username string `yaml:"username"` // basic auth username, optional
+	Password string `yaml:"password"` // basic auth password, optional
+	CAFile   string `yaml:"caFile"`   // ca certificate path, optional
+	CertFile string `yaml:"certFile"` // client certificate path, optional
+	KeyFile  string `yaml:"keyFile"`  // client key path, optional
+}
+
+// Entry as Helm repository entry, with index cached in Helm home.
+func (c *ChartRepositorySpec) Entry(home helmpath.Home) *repo.Entry {
+	return &repo.Entry{
+		Name:     c.Name,
+		Cache:    home.CacheIndex(c.Name),
+		URL:      os.ExpandEnv(c.URL),
+		Username: os.ExpandEnv(c.Username),
+		Password: os.ExpandEnv(c.Password),
+		CAFile:   os.ExpandEnv(c.CAFile),
+		CertFile: os.ExpandEnv(c.CertFile),
+		KeyFile:  os.ExpandEnv(c.KeyFile),
+	}
 }
 
 // Environment representation, related to environment scope and transformation
 type Environment struct {
-	Name             string    `yaml:"name"`
-	SkipOnNamespaces []string  `yaml:"skipOnNamespaces"`
-	OnlyOnNamespaces []string  `yaml:"onlyOnNamespaces"`
-	FileSuffixes     []string  `yaml:"fileSuffixes"`
-	Transform        Transform `yaml:"transform"`
+	Name             string            `yaml:"name"`
+	SkipOnNamespaces []string          `yaml:"skipOnNamespaces"`
+	OnlyOnNamespaces []string          `yaml:"onlyOnNamespaces"`
+	FileSuffixes     []string          `yaml:"fileSuffixes"`
+	Transform        Transform         `yaml:"transform"`
+	Secrets          SecretsSpec       `yaml:"secrets"`
+	Tiller           TillerSpec        `yaml:"tiller"`
+	ChartVersions    map[string]string `yaml:"chartVersions"` // version or constraint per chart
 }
 
 // Transform configuration on how to transform a release for that environment
@@ -35,11 +72,103 @@ type Transform struct {
 	ReleasePrefix   string `yaml:"releasePrefix"`
 }
 
+// SecretsSpec configuration on where secrets are read from, on environment
+type SecretsSpec struct {
+	Source         string `yaml:"source"`         // secret source kind, "vault" (default) or "file"
+	Dir            string `yaml:"dir"`            // base directory for file source
+	KeyRing        string `yaml:"keyRing"`        // gpg key-ring file for file source, optional
+	LandscaperPath string `yaml:"landscaperPath"` // path for landscaper component secrets
+}
+
+// GetSource returns secret source kind, using Vault by default.
+func (s *SecretsSpec) GetSource() string {
+	if s.Source == "" {
+		return SecretSourceVault
+	}
+	return s.Source
+}
+
+// Validate secret source configuration.
+func (s *SecretsSpec) Validate() error {
+	switch s.GetSource() {
+	case SecretSourceVault:
+		return nil
+	case SecretSourceFile:
+		if !isDir(s.Dir) {
+			return fmt.Errorf("secrets dir is not a directory '%s'", s.Dir)
+		}
+		if s.KeyRing != "" && !fileExists(s.KeyRing) {
+			return fmt.Errorf("secrets key-ring is not found '%s'", s.KeyRing)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown secret source '%s'", s.Source)
+	}
+}
+
+// TillerSpec Helm's Tiller configuration on environment, overrides command-line
+type TillerSpec struct {
+	TLS         bool   `yaml:"tls"`         // enable tls
+	TLSVerify   bool   `yaml:"tlsVerify"`   // enable tls and verify tiller certificate
+	TLSCaCert   string `yaml:"tlsCaCert"`   // path to ca certificate
+	TLSCert     string `yaml:"tlsCert"`     // path to client certificate
+	TLSKey      string `yaml:"tlsKey"`      // path to client key
+	TLSHostname string `yaml:"tlsHostname"` // tiller server name
+}
+
+// Merge environment Tiller settings on top of informed TLS configuration.
+func (t *TillerSpec) Merge(tlsCfg TillerTLSConfig) TillerTLSConfig {
+	tlsCfg.TLSEnable = tlsCfg.TLSEnable || t.TLS
+	tlsCfg.TLSVerify = tlsCfg.TLSVerify || t.TLSVerify
+	if t.TLSCaCert != "" {
+		tlsCfg.TLSCaCert = t.TLSCaCert
+	}
+	if t.TLSCert != "" {
+		tlsCfg.TLSCert = t.TLSCert
+	}
+	if t.TLSKey != "" {
+		tlsCfg.TLSKey = t.TLSKey
+	}
+	if t.TLSHostname != "" {
+		tlsCfg.TLSServerName = t.TLSHostname
+	}
+	return tlsCfg
+}
+
 // Namespaces in kubernetes, representation to where to find namespace directories and releases
 type Namespaces struct {
-	BaseDir    string   `yaml:"baseDir"`
-	Extensions []string `yaml:"extensions"`
-	Names      []string `yaml:"names"`
+	BaseDir    string              `yaml:"baseDir"`
+	Extensions []string            `yaml:"extensions"`
+	Names      []NamespaceSpec     `yaml:"names"`
+	FileKinds  map[string][]string `yaml:"fileKinds"` // file name patterns per file kind
+}
+
+// NamespaceSpec namespace entry, informed as a plain name, or with Kubernetes namespace settings
+type NamespaceSpec struct {
+	Name        string            `yaml:"name"`        // namespace name
+	Create      bool              `yaml:"create"`      // create namespace when missing
+	Labels      map[string]string `yaml:"labels"`      // namespace labels
+	Annotations map[string]string `yaml:"annotations"` // namespace annotations
+}
+
+// namespaceSpec alias to unmarshal namespace entry without recursion.
+type namespaceSpec NamespaceSpec
+
+// UnmarshalYAML accepts either a plain namespace name, or the complete namespace entry.
+func (n *NamespaceSpec) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var spec namespaceSpec
+
+	if err := unmarshal(&n.Name); err == nil {
+		return nil
+	}
+	if err := unmarshal(&spec); err != nil {
+		return err
+	}
+	if spec.Name == "" {
+		return fmt.Errorf("namespace entry without name")
+	}
+	*n = NamespaceSpec(spec)
+	return nil
 }
 
 // Interpolate a string based on Environment attributes, plus whats informed.
@@ -53,9 +182,29 @@ func (e *Environment) Interpolate(str string, placeholders []string) (string, er
 	return interpolate.Interpolate(sliceEnv, str)
 }
 
+// TargetNamespace namespace name on environment, with namespace prefix and suffix.
+func (e *Environment) TargetNamespace(ns string) string {
+	return fmt.Sprintf("%s%s%s", e.Transform.NamespacePrefix, ns, e.Transform.NamespaceSuffix)
+}
+
 // ListNamespaces exposes the list with namespace names.
 func (d *DotGalaxy) ListNamespaces() []string {
-	return d.Spec.Namespaces.Names
+	var list []string
+	for _, ns := range d.Spec.Namespaces.Names {
+		list = append(list, ns.Name)
+	}
+	return list
+}
+
+// GetNamespaceSpec return namespace entry based on its name.
+func (d *DotGalaxy) GetNamespaceSpec(name string) (*NamespaceSpec, error) {
+	for _, ns := range d.Spec.Namespaces.Names {
+		if name == ns.Name {
+			spec := ns
+			return &spec, nil
+		}
+	}
+	return nil, fmt.Errorf("namespace is not found '%s'", name)
 }
 
 // ListEnvironments names based in known configuration.
@@ -69,7 +218,7 @@ func (d *DotGalaxy) ListEnvironments() []string {
 
 // GetNamespaceDir returns the path to the namespace directory, or error
 func (d *DotGalaxy) GetNamespaceDir(name string) (string, error) {
-	if !stringSliceContains(d.Spec.Namespaces.Names, name) {
+	if !stringSliceContains(d.ListNamespaces(), name) {
 		return "", fmt.Errorf("namespace informed does not exist '%s'", name)
 	}
 	if !isDir(d.Spec.Namespaces.BaseDir) {
@@ -89,11 +238,16 @@ func (d *DotGalaxy) GetEnvironment(name string) (*Environment, error) {
 	return nil, fmt.Errorf("environment is not found '%s'", name)
 }
 
-// NewDotGalaxy to load `.galaxy.yml` file.
-func NewDotGalaxy(filePath string) (*DotGalaxy, error) {
+// parseDotGalaxy parse dot-galaxy payload.
+func parseDotGalaxy(payload []byte) (*DotGalaxy, error) {
 	dotGalaxy := &DotGalaxy{}
-	if err := yaml.Unmarshal(readFile(filePath), dotGalaxy); err != nil {
+	if err := yaml.Unmarshal(payload, dotGalaxy); err != nil {
 		return nil, err
 	}
 	return dotGalaxy, nil
 }
+
+// NewDotGalaxy to load `.galaxy.yml` file.
+func NewDotGalaxy(filePath string) (*DotGalaxy, error) {
+	return parseDotGalaxy(readFile(filePath))
+}
@@ -54,3 +54,44 @@ func TestDotGalaxyGetEnvironment(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, "dev", env.Name)
 }
+
+func TestDotGalaxySecretsSpec(t *testing.T) {
+	spec := SecretsSpec{}
+	assert.Equal(t, SecretSourceVault, spec.GetSource())
+	assert.Nil(t, spec.Validate())
+
+	spec = SecretsSpec{Source: SecretSourceFile, Dir: "../../test/namespaces"}
+	assert.Nil(t, spec.Validate())
+	spec.Dir = "/does/not/exist"
+	assert.NotNil(t, spec.Validate())
+
+	spec = SecretsSpec{Source: "unknown"}
+	assert.NotNil(t, spec.Validate())
+}
+
+func TestDotGalaxyTillerSpecMerge(t *testing.T) {
+	spec := TillerSpec{TLS: true, TLSKey: "/env/key.pem", TLSHostname: "tiller"}
+	tlsCfg := spec.Merge(TillerTLSConfig{TLSVerify: true, TLSKey: "/cli/key.pem", TLSCert: "/cli/cert.pem"})
+
+	assert.True(t, tlsCfg.TLSEnable)
+	assert.True(t, tlsCfg.TLSVerify)
+	assert.Equal(t, "/env/key.pem", tlsCfg.TLSKey)
+	assert.Equal(t, "/cli/cert.pem", tlsCfg.TLSCert)
+	assert.Equal(t, "tiller", tlsCfg.TLSServerName)
+}
+
+func TestDotGalaxyGetNamespaceSpec(t *testing.T) {
+	assert.Equal(t, []string{"ns1", "ns2", "ns3", "ns4"}, dotGalaxy.ListNamespaces())
+
+	spec, err := dotGalaxy.GetNamespaceSpec("ns1")
+	assert.Nil(t, err)
+	assert.False(t, spec.Create)
+
+	spec, err = dotGalaxy.GetNamespaceSpec("ns2")
+	assert.Nil(t, err)
+	assert.True(t, spec.Create)
+	assert.Equal(t, map[string]string{"team": "galaxy"}, spec.Labels)
+
+	_, err = dotGalaxy.GetNamespaceSpec("ns5")
+	assert.NotNil(t, err)
+}
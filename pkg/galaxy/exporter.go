@@ -0,0 +1,372 @@
+package galaxy
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	ldsc "github.com/Eneco/landscaper/pkg/landscaper"
+	ghodssyaml "github.com/ghodss/yaml"
+	log "github.com/sirupsen/logrus"
+	yaml "gopkg.in/yaml.v2"
+	"k8s.io/helm/pkg/helm/helmpath"
+)
+
+const (
+	// ExportHelmfile export format generating a "helmfile.yaml"
+	ExportHelmfile = "helmfile"
+	// ExportArgoCD export format generating Argo CD Application manifests
+	ExportArgoCD = "argocd"
+)
+
+// ExportFormats supported export formats.
+var ExportFormats = []string{ExportHelmfile, ExportArgoCD}
+
+// envReferenceRe matches values made of a single environment variable reference, as in "${NAME}".
+var envReferenceRe = regexp.MustCompile(`^\$\{?([A-Za-z_][A-Za-z0-9_]*)\}?$`)
+
+// envNameInvalidRe matches characters not allowed on environment variable names.
+var envNameInvalidRe = regexp.MustCompile(`[^A-Z0-9_]`)
+
+// ArgoCDSpec settings employed on Argo CD Application manifests.
+type ArgoCDSpec struct {
+	Namespace string // namespace where Argo CD is installed
+	Project   string // Argo CD project
+	Server    string // destination Kubernetes API server
+	RepoURL   string // git repository url, employed by local charts
+}
+
+// ExportedFile file generated by exporter, name and contents.
+type ExportedFile struct {
+	Name    string // file name
+	Payload []byte // file contents
+}
+
+// Exporter converts planned releases of an environment to other GitOps tools formats, keeping
+// renamed namespaces and release names. Component secrets are not exported.
+type Exporter struct {
+	logger       *log.Entry            // logger
+	env          *Environment          // environment instance
+	ctxs         []*Context            // planned contexts for environment
+	repos        []ChartRepositorySpec // chart repositories declared on dot-galaxy
+	charts       *LocalCharts          // local chart directories
+	overrideFile string                // landscaper configuration override file, optional
+}
+
+// exportedRelease planned release, with chart reference split and values for environment.
+type exportedRelease struct {
+	name      string                 // release name, with prefix
+	namespace string                 // target namespace
+	repo      string                 // chart repository name, empty for local charts
+	chart     string                 // chart name, or local chart directory
+	version   string                 // chart version or constraint
+	values    map[string]interface{} // release values
+}
+
+// Export planned releases in informed format.
+func (e *Exporter) Export(format string, argo ArgoCDSpec) ([]ExportedFile, error) {
+	switch format {
+	case ExportHelmfile:
+		return e.Helmfile()
+	case ExportArgoCD:
+		return e.ArgoCD(argo)
+	}
+	return nil, fmt.Errorf("export format '%s' is not supported, expected one of '%s'",
+		format, strings.Join(ExportFormats, ", "))
+}
+
+// Helmfile generate "helmfile.yaml", with chart repositories employed and a entry per release.
+// Repository credentials are read by helmfile from environment variables.
+func (e *Exporter) Helmfile() ([]ExportedFile, error) {
+	var releases []exportedRelease
+	var repos []yaml.MapSlice
+	var entries []yaml.MapSlice
+	var payload []byte
+	var err error
+
+	if releases, err = e.releases(); err != nil {
+		return nil, err
+	}
+	for _, spec := range e.repos {
+		if !e.repoInUse(releases, spec.Name) {
+			continue
+		}
+		entry := spec.Entry(helmpath.Home(""))
+		repo := yaml.MapSlice{{Key: "name", Value: entry.Name}, {Key: "url", Value: entry.URL}}
+		if spec.Username != "" {
+			repo = append(repo, yaml.MapItem{
+				Key: "username", Value: e.requiredEnv(spec.Name, "username", spec.Username),
+			})
+		}
+		if spec.Password != "" {
+			repo = append(repo, yaml.MapItem{
+				Key: "password", Value: e.requiredEnv(spec.Name, "password", spec.Password),
+			})
+		}
+		for _, file := range []yaml.MapItem{
+			{Key: "caFile", Value: entry.CAFile},
+			{Key: "certFile", Value: entry.CertFile},
+			{Key: "keyFile", Value: entry.KeyFile},
+		} {
+			if file.Value != "" {
+				repo = append(repo, file)
+			}
+		}
+		repos = append(repos, repo)
+	}
+
+	for _, r := range releases {
+		chart := r.chart
+		if r.repo != "" {
+			chart = fmt.Sprintf("%s/%s", r.repo, r.chart)
+		} else if !filepath.IsAbs(chart) && !strings.HasPrefix(chart, "../") {
+			// local charts are told apart from repository charts by path prefix
+			chart = fmt.Sprintf("./%s", chart)
+		}
+		entry := yaml.MapSlice{
+			{Key: "name", Value: r.name},
+			{Key: "namespace", Value: r.namespace},
+			{Key: "chart", Value: chart},
+		}
+		if r.version != "" {
+			entry = append(entry, yaml.MapItem{Key: "version", Value: r.version})
+		}
+		if len(r.values) > 0 {
+			entry = append(entry, yaml.MapItem{Key: "values", Value: []interface{}{r.values}})
+		}
+		entries = append(entries, entry)
+	}
+
+	doc := yaml.MapSlice{}
+	if len(repos) > 0 {
+		doc = append(doc, yaml.MapItem{Key: "repositories", Value: repos})
+	}
+	doc = append(doc, yaml.MapItem{Key: "releases", Value: entries})
+	if payload, err = yaml.Marshal(doc); err != nil {
+		return nil, err
+	}
+	payload = append([]byte("---\n"), payload...)
+	return []ExportedFile{{Name: "helmfile.yaml", Payload: payload}}, nil
+}
+
+// ArgoCD generate an Application manifest per release. Charts are sourced from the chart
+// repository url declared on dot-galaxy, and local charts from informed git repository.
+func (e *Exporter) ArgoCD(argo ArgoCDSpec) ([]ExportedFile, error) {
+	var releases []exportedRelease
+	var files []ExportedFile
+	var err error
+
+	if releases, err = e.releases(); err != nil {
+		return nil, err
+	}
+	for _, r := range releases {
+		var payload, values []byte
+		var err error
+
+		helmSpec := yaml.MapSlice{{Key: "releaseName", Value: r.name}}
+		if len(r.values) > 0 {
+			if values, err = yaml.Marshal(r.values); err != nil {
+				return nil, err
+			}
+			helmSpec = append(helmSpec, yaml.MapItem{Key: "values", Value: string(values)})
+		}
+
+		source := yaml.MapSlice{}
+		if r.repo == "" {
+			if argo.RepoURL == "" {
+				return nil, fmt.Errorf(
+					"release '%s': local chart '%s' requires git repository url", r.name, r.chart)
+			}
+			source = append(source,
+				yaml.MapItem{Key: "repoURL", Value: argo.RepoURL},
+				yaml.MapItem{Key: "path", Value: r.chart},
+				yaml.MapItem{Key: "targetRevision", Value: "HEAD"},
+			)
+		} else {
+			url, found := e.repoURL(r.repo)
+			if !found {
+				return nil, fmt.Errorf("release '%s': repository '%s' is not declared on charts",
+					r.name, r.repo)
+			}
+			source = append(source,
+				yaml.MapItem{Key: "repoURL", Value: url},
+				yaml.MapItem{Key: "chart", Value: r.chart},
+				yaml.MapItem{Key: "targetRevision", Value: r.version},
+			)
+		}
+		source = append(source, yaml.MapItem{Key: "helm", Value: helmSpec})
+
+		if payload, err = yaml.Marshal(yaml.MapSlice{
+			{Key: "apiVersion", Value: "argoproj.io/v1alpha1"},
+			{Key: "kind", Value: "Application"},
+			{Key: "metadata", Value: yaml.MapSlice{
+				{Key: "name", Value: r.name},
+				{Key: "namespace", Value: argo.Namespace},
+				{Key: "labels", Value: map[string]string{
+					ManagedByLabel: ManagedByValue, EnvironmentLabel: e.env.Name,
+				}},
+			}},
+			{Key: "spec", Value: yaml.MapSlice{
+				{Key: "project", Value: argo.Project},
+				{Key: "source", Value: source},
+				{Key: "destination", Value: yaml.MapSlice{
+					{Key: "server", Value: argo.Server},
+					{Key: "namespace", Value: r.namespace},
+				}},
+			}},
+		}); err != nil {
+			return nil, err
+		}
+		files = append(files, ExportedFile{
+			Name:    fmt.Sprintf("%s.yaml", r.name),
+			Payload: append([]byte("---\n"), payload...),
+		})
+	}
+	return files, nil
+}
+
+// releases planned on environment, sorted by namespace and release name.
+func (e *Exporter) releases() ([]exportedRelease, error) {
+	var releases []exportedRelease
+
+	for _, ctx := range e.ctxs {
+		for ns, nsReleases := range ctx.Releases {
+			for _, release := range nsReleases {
+				var values ldsc.Configuration
+				var err error
+
+				if values, err = e.values(release.Component); err != nil {
+					return nil, fmt.Errorf("release '%s': %s", release.Component.Name, err)
+				}
+				delete(values, landscaperMetadataKey)
+				if release.Component.SecretsRaw != nil {
+					e.logger.Warnf("Release '%s' secrets are not exported", release.Component.Name)
+				}
+
+				name, version := splitChartRef(release.Component.Release.Chart)
+				r := exportedRelease{
+					name:      release.Component.Name,
+					namespace: ns,
+					chart:     e.charts.ChartDir(name),
+					version:   version,
+					values:    values,
+				}
+				if !isLocalChartRef(name) {
+					parts := strings.SplitN(name, "/", 2)
+					r.repo, r.chart = parts[0], parts[len(parts)-1]
+				}
+				releases = append(releases, r)
+			}
+		}
+	}
+	sort.Slice(releases, func(a, b int) bool {
+		if releases[a].namespace != releases[b].namespace {
+			return releases[a].namespace < releases[b].namespace
+		}
+		return releases[a].name < releases[b].name
+	})
+	return releases, nil
+}
+
+// values release configuration merged with override file and environment configuration, in this
+// order, as Landscaper does.
+func (e *Exporter) values(component *Component) (ldsc.Configuration, error) {
+	var cfg, overrideCfg, envCfg ldsc.Configuration
+	var payload []byte
+	var err error
+
+	if cfg, err = landscaperConfiguration(component.Configuration); err != nil {
+		return nil, err
+	}
+	if e.overrideFile != "" {
+		if payload, err = ioutil.ReadFile(e.overrideFile); err != nil {
+			return nil, err
+		}
+		if err = ghodssyaml.Unmarshal(payload, &overrideCfg); err != nil {
+			return nil, err
+		}
+		cfg = cfg.Merge(overrideCfg)
+	}
+	if envCfg, err = landscaperConfiguration(component.Environments[e.env.Name]); err != nil {
+		return nil, err
+	}
+	return cfg.Merge(envCfg), nil
+}
+
+// repoInUse checks if chart repository is employed by releases.
+func (e *Exporter) repoInUse(releases []exportedRelease, repoName string) bool {
+	for _, r := range releases {
+		if r.repo == repoName {
+			return true
+		}
+	}
+	return false
+}
+
+// repoURL url of chart repository declared on dot-galaxy, with environment variables expanded.
+func (e *Exporter) repoURL(repoName string) (string, bool) {
+	for _, spec := range e.repos {
+		if spec.Name == repoName {
+			return spec.Entry(helmpath.Home("")).URL, true
+		}
+	}
+	return "", false
+}
+
+// requiredEnv helmfile template reading a repository credential from environment variable. Values
+// referring to a single variable keep its name, otherwise the name is based on repository name and
+// field, as in "HELM_REPO_STABLE_PASSWORD", so credentials are never written on exported files.
+func (e *Exporter) requiredEnv(repoName, field, value string) string {
+	var name string
+
+	if match := envReferenceRe.FindStringSubmatch(value); match != nil {
+		name = match[1]
+	} else {
+		name = strings.ToUpper(fmt.Sprintf("HELM_REPO_%s_%s", repoName, field))
+		name = envNameInvalidRe.ReplaceAllString(name, "_")
+		e.logger.Warnf("Repository '%s' %s is read from environment variable '%s'",
+			repoName, field, name)
+	}
+	return fmt.Sprintf(`{{ requiredEnv "%s" }}`, name)
+}
+
+// landscaperConfiguration decode configuration as Landscaper does, with nested maps keyed by
+// string, so configuration merge applies on nested maps.
+func landscaperConfiguration(cfg ldsc.Configuration) (ldsc.Configuration, error) {
+	var decoded ldsc.Configuration
+	var payload []byte
+	var err error
+
+	if payload, err = yaml.Marshal(cfg); err != nil {
+		return nil, err
+	}
+	if err = ghodssyaml.Unmarshal(payload, &decoded); err != nil {
+		return nil, err
+	}
+	if decoded == nil {
+		decoded = ldsc.Configuration{}
+	}
+	return decoded, nil
+}
+
+// NewExporter instantiate exporter of planned contexts for environment, local charts are relative
+// to base directory. Override file is Landscaper configuration override, optional.
+func NewExporter(
+	env *Environment,
+	ctxs []*Context,
+	repos []ChartRepositorySpec,
+	baseDir string,
+	overrideFile string,
+) *Exporter {
+	return &Exporter{
+		logger:       log.WithFields(log.Fields{"type": "exporter", "env": env.Name}),
+		env:          env,
+		ctxs:         ctxs,
+		repos:        repos,
+		charts:       NewLocalCharts(baseDir, ""),
+		overrideFile: overrideFile,
+	}
+}
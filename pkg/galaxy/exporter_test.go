@@ -0,0 +1,199 @@
+package galaxy
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	ldsc "github.com/Eneco/landscaper/pkg/landscaper"
+	"github.com/stretchr/testify/assert"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// exporterContext planned context with a repository chart release, carrying environment specific
+// configuration, and a local chart release.
+func exporterContext() *Context {
+	ctx := NewContext()
+	ctx.Releases["ns1-d"] = []Release{{
+		Namespace: "ns1-d",
+		Component: &Component{
+			Name:    "d-ns1-web",
+			Release: &ldsc.Release{Chart: "stable/app:0.1.0"},
+			Configuration: ldsc.Configuration{
+				"replicas": 1,
+				"image":    map[interface{}]interface{}{"name": "app", "tag": "latest"},
+			},
+			Environments: ldsc.Configurations{
+				"dev": ldsc.Configuration{
+					"image": map[interface{}]interface{}{"tag": "dev"},
+				},
+			},
+		},
+	}, {
+		Namespace: "ns1-d",
+		Component: &Component{
+			Name:    "d-ns1-api",
+			Release: &ldsc.Release{Chart: "./charts/api"},
+		},
+	}}
+	return ctx
+}
+
+func TestExporterHelmfile(t *testing.T) {
+	env := &Environment{Name: "dev"}
+	e := NewExporter(env, []*Context{exporterContext()}, []ChartRepositorySpec{
+		{Name: "stable", URL: "https://charts.example.com"},
+		{Name: "unused", URL: "https://unused.example.com"},
+	}, "namespaces", "")
+
+	files, err := e.Export(ExportHelmfile, ArgoCDSpec{})
+	assert.Nil(t, err)
+	assert.Len(t, files, 1)
+	assert.Equal(t, "helmfile.yaml", files[0].Name)
+
+	var helmfile struct {
+		Repositories []map[string]string `yaml:"repositories"`
+		Releases     []struct {
+			Name      string                   `yaml:"name"`
+			Namespace string                   `yaml:"namespace"`
+			Chart     string                   `yaml:"chart"`
+			Version   string                   `yaml:"version"`
+			Values    []map[string]interface{} `yaml:"values"`
+		} `yaml:"releases"`
+	}
+	assert.Nil(t, yaml.Unmarshal(files[0].Payload, &helmfile))
+	assert.Equal(t, []map[string]string{
+		{"name": "stable", "url": "https://charts.example.com"},
+	}, helmfile.Repositories)
+	assert.Len(t, helmfile.Releases, 2)
+
+	api, web := helmfile.Releases[0], helmfile.Releases[1]
+	assert.Equal(t, "d-ns1-api", api.Name)
+	assert.Equal(t, "./namespaces/charts/api", api.Chart)
+	assert.Empty(t, api.Values)
+
+	assert.Equal(t, "d-ns1-web", web.Name)
+	assert.Equal(t, "ns1-d", web.Namespace)
+	assert.Equal(t, "stable/app", web.Chart)
+	assert.Equal(t, "0.1.0", web.Version)
+	assert.Len(t, web.Values, 1)
+	assert.Equal(t, map[interface{}]interface{}{"name": "app", "tag": "dev"},
+		web.Values[0]["image"])
+
+	_, err = e.Export("other", ArgoCDSpec{})
+	assert.NotNil(t, err)
+}
+
+func TestExporterArgoCD(t *testing.T) {
+	env := &Environment{Name: "dev"}
+	argo := ArgoCDSpec{
+		Namespace: "argocd", Project: "default", Server: "https://kubernetes.default.svc",
+	}
+	e := NewExporter(env, []*Context{exporterContext()}, []ChartRepositorySpec{
+		{Name: "stable", URL: "https://charts.example.com"},
+	}, "namespaces", "")
+
+	// local charts require git repository url
+	_, err := e.Export(ExportArgoCD, argo)
+	assert.NotNil(t, err)
+
+	argo.RepoURL = "https://git.example.com/repo.git"
+	files, err := e.Export(ExportArgoCD, argo)
+	assert.Nil(t, err)
+	assert.Len(t, files, 2)
+	assert.Equal(t, "d-ns1-api.yaml", files[0].Name)
+	assert.Equal(t, "d-ns1-web.yaml", files[1].Name)
+
+	var app struct {
+		Kind     string `yaml:"kind"`
+		Metadata struct {
+			Name      string            `yaml:"name"`
+			Namespace string            `yaml:"namespace"`
+			Labels    map[string]string `yaml:"labels"`
+		} `yaml:"metadata"`
+		Spec struct {
+			Source struct {
+				RepoURL        string `yaml:"repoURL"`
+				Chart          string `yaml:"chart"`
+				Path           string `yaml:"path"`
+				TargetRevision string `yaml:"targetRevision"`
+				Helm           struct {
+					ReleaseName string `yaml:"releaseName"`
+					Values      string `yaml:"values"`
+				} `yaml:"helm"`
+			} `yaml:"source"`
+			Destination struct {
+				Namespace string `yaml:"namespace"`
+			} `yaml:"destination"`
+		} `yaml:"spec"`
+	}
+
+	assert.Nil(t, yaml.Unmarshal(files[0].Payload, &app))
+	assert.Equal(t, "https://git.example.com/repo.git", app.Spec.Source.RepoURL)
+	assert.Equal(t, "namespaces/charts/api", app.Spec.Source.Path)
+	assert.Equal(t, "HEAD", app.Spec.Source.TargetRevision)
+
+	assert.Nil(t, yaml.Unmarshal(files[1].Payload, &app))
+	assert.Equal(t, "Application", app.Kind)
+	assert.Equal(t, "d-ns1-web", app.Metadata.Name)
+	assert.Equal(t, "argocd", app.Metadata.Namespace)
+	assert.Equal(t, "dev", app.Metadata.Labels[EnvironmentLabel])
+	assert.Equal(t, "https://charts.example.com", app.Spec.Source.RepoURL)
+	assert.Equal(t, "app", app.Spec.Source.Chart)
+	assert.Equal(t, "0.1.0", app.Spec.Source.TargetRevision)
+	assert.Equal(t, "d-ns1-web", app.Spec.Source.Helm.ReleaseName)
+	assert.Contains(t, app.Spec.Source.Helm.Values, "tag: dev")
+	assert.Equal(t, "ns1-d", app.Spec.Destination.Namespace)
+}
+
+func TestExporterValues(t *testing.T) {
+	overrideFile, err := ioutil.TempFile("", "galaxy-override")
+	assert.Nil(t, err)
+	defer os.Remove(overrideFile.Name())
+	_, err = overrideFile.WriteString("replicas: 3\nresources:\n  cpu: 100m\n")
+	assert.Nil(t, err)
+	assert.Nil(t, overrideFile.Close())
+
+	ctx := exporterContext()
+	e := NewExporter(&Environment{Name: "dev"}, []*Context{ctx}, nil, "", overrideFile.Name())
+
+	// override file comes before environment configuration
+	values, err := e.values(ctx.Releases["ns1-d"][0].Component)
+	assert.Nil(t, err)
+	assert.Equal(t, ldsc.Configuration{
+		"replicas":  float64(3),
+		"image":     ldsc.Configuration{"name": "app", "tag": "dev"},
+		"resources": map[string]interface{}{"cpu": "100m"},
+	}, values)
+	assert.Equal(t, 1, ctx.Releases["ns1-d"][0].Component.Configuration["replicas"])
+}
+
+func TestExporterRepositories(t *testing.T) {
+	os.Setenv("GALAXY_TEST_REPO_HOST", "charts.example.com")
+	defer os.Unsetenv("GALAXY_TEST_REPO_HOST")
+
+	e := NewExporter(&Environment{Name: "dev"}, []*Context{exporterContext()},
+		[]ChartRepositorySpec{{
+			Name:     "stable",
+			URL:      "https://${GALAXY_TEST_REPO_HOST}",
+			Username: "${REPO_USERNAME}",
+			Password: "secret",
+			CAFile:   "/certs/ca.pem",
+		}}, "namespaces", "")
+
+	files, err := e.Export(ExportHelmfile, ArgoCDSpec{})
+	assert.Nil(t, err)
+
+	var helmfile struct {
+		Repositories []map[string]string `yaml:"repositories"`
+	}
+	assert.Nil(t, yaml.Unmarshal(files[0].Payload, &helmfile))
+	assert.Equal(t, []map[string]string{{
+		"name":     "stable",
+		"url":      "https://charts.example.com",
+		"username": `{{ requiredEnv "REPO_USERNAME" }}`,
+		"password": `{{ requiredEnv "HELM_REPO_STABLE_PASSWORD" }}`,
+		"caFile":   "/certs/ca.pem",
+	}}, helmfile.Repositories)
+	assert.NotContains(t, string(files[0].Payload), "secret")
+}
@@ -2,8 +2,11 @@ package galaxy
 
 import (
 	"fmt"
+	"sort"
 
 	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/restmapper"
 )
 
 // Galaxy holds application runtime items
@@ -14,6 +17,8 @@ type Galaxy struct {
 	original      Data                         // original contexts per env
 	Modified      Data                         // modified contexts per env
 	envOriginalNs map[string]map[string]string // mapping original namespace names per env
+	clients       *Clients                     // kubernetes and helm clients cache
+	Restarts      []Restart                    // workloads restarted during apply
 }
 
 // Data belonging to Galaxy, having environment name as key and a list of contexts
@@ -70,7 +75,12 @@ func (g *Galaxy) Plan() error {
 func (g *Galaxy) Apply() error {
 	var e *Environment
 	var envName string
-	var v *VaultHandler
+	var s SecretsApplier
+	var source SecretSource
+	var o *SecretsOwner
+	var r *Restarter
+	var m *ManifestHandler
+	var kubeClient *KubeClient
 	var err error
 
 	g.logger.Infof("DRY-RUN: '%v', Environment: '%s'", g.cfg.DryRun, g.cfg.GetEnvironments())
@@ -86,18 +96,87 @@ func (g *Galaxy) Apply() error {
 		return err
 	}
 
+	if g.cfg.SkipPreflight {
+		logger.Warn("Skipping pre-flight checks!")
+	} else if err = g.preflight(e, envName); err != nil {
+		return err
+	}
+
+	if kubeClient, err = g.clients.Kube(g.cfg.KubeContext); err != nil {
+		return err
+	}
+	n := NewNamespaceHandler(kubeClient.Client.Core(), e, g.cfg.DryRun)
+	baseDir := g.dotGalaxy.Spec.Namespaces.BaseDir
+	p := NewPolicyHandler(kubeClient.Dynamic, envName, baseDir, g.Modified[envName], g.cfg.DryRun)
+	if m, err = g.manifestHandler(envName, kubeClient); err != nil {
+		return err
+	}
+
 	if !g.cfg.SkipSecrets {
-		v = NewVaultHandler(g.cfg.VaultHandlerConfig, g.cfg.KubernetesConfig, g.Modified[envName])
+		if s, err = g.secretsApplier(e, g.Modified[envName], kubeClient); err != nil {
+			return err
+		}
+		defer s.Close()
+		source = s.Source()
+		if err = g.validateSecrets(source, envName); err != nil {
+			return err
+		}
+		o = NewSecretsOwner(
+			kubeClient.Client.Core(), envName, baseDir, g.Modified[envName], g.cfg.DryRun)
+		r = NewRestarter(kubeClient.Client, envName, g.Modified[envName], source, g.cfg.DryRun)
 	}
 
-	l := NewLandscaper(g.cfg.LandscaperConfig, g.cfg.KubernetesConfig, e, g.Modified[envName], g.cfg.Raw)
+	lc := NewLocalCharts(baseDir, g.cfg.HelmHome)
+	defer lc.Close()
+	l := NewLandscaper(g.cfg.LandscaperConfig, g.cfg.KubernetesConfig, g.clients, e,
+		g.Modified[envName], source, lc, g.cfg.Raw)
 	for ns, originalNs := range g.envOriginalNs[envName] {
+		var spec *NamespaceSpec
+		var changed []string
+
+		if spec, err = g.dotGalaxy.GetNamespaceSpec(originalNs); err != nil {
+			return err
+		}
+		if err = n.Ensure(ns, originalNs, spec); err != nil {
+			return err
+		}
+		if err = p.Apply(ns); err != nil {
+			return err
+		}
+		if g.cfg.PrunePolicies {
+			if err = p.Prune(ns); err != nil {
+				return err
+			}
+		}
+		if err = m.Apply(ns); err != nil {
+			return err
+		}
+		if g.cfg.PruneManifests {
+			if err = m.Prune(ns); err != nil {
+				return err
+			}
+		}
+
 		if !g.cfg.SkipSecrets {
 			logger.Infof("Handling secrets for '%s' namespace", ns)
-			if err = v.Bootstrap(ns, g.cfg.DryRun); err != nil {
+			if err = r.Snapshot(ns); err != nil {
+				return err
+			}
+			if err = s.Bootstrap(ns, g.cfg.DryRun); err != nil {
 				return err
 			}
-			if err = v.Apply(); err != nil {
+			if err = s.Apply(); err != nil {
+				return err
+			}
+			if err = o.Tag(ns); err != nil {
+				return err
+			}
+			if g.cfg.PruneSecrets {
+				if err = o.Prune(ns); err != nil {
+					return err
+				}
+			}
+			if changed, err = r.Changed(ns); err != nil {
 				return err
 			}
 		}
@@ -109,33 +188,420 @@ func (g *Galaxy) Apply() error {
 		if err = l.Apply(); err != nil {
 			return err
 		}
+
+		if len(changed) > 0 {
+			logger.Infof("Secrets changed on namespace '%s': '%v'", ns, changed)
+			if err = r.Restart(ns, changed); err != nil {
+				return err
+			}
+			g.Restarts = r.Restarts
+		}
 	}
 	return nil
 }
 
-// Loop over environments and its contexts.
-func (g *Galaxy) Loop(fn actOnContext) error {
-	var exts = g.dotGalaxy.Spec.Namespaces.Extensions
+// preflight run doctor checks before changing any namespace, failed checks are logged.
+func (g *Galaxy) preflight(env *Environment, envName string) error {
+	logger := g.logger.WithField("env", envName)
+	logger.Info("Running pre-flight checks...")
+
+	d := NewDoctor(g.cfg, g.clients, env, g.Modified[envName], g.namespaces(envName),
+		g.namespaceSpecs(envName), g.dotGalaxy.Spec.Namespaces.BaseDir)
+	err := d.Run()
+	for _, check := range d.Checks {
+		if check.Status == CheckFail {
+			logger.Errorf("Pre-flight check '%s' failed on '%s': %s",
+				check.Name, check.Target, check.Message)
+		}
+	}
+	return err
+}
+
+// Doctor run connectivity and permission checks for the planned environment, returns the checks,
+// and error when any of them failed.
+func (g *Galaxy) Doctor() ([]Check, error) {
+	var envName string
+	var env *Environment
 	var err error
 
-	logger := g.logger.WithField("exts", exts)
-	for _, env := range g.dotGalaxy.ListEnvironments() {
-		ctx := NewContext()
-		logger = g.logger.WithField("env", env)
+	if envName, err = g.probeSingleEnv(); err != nil {
+		return nil, err
+	}
+	if env, err = g.dotGalaxy.GetEnvironment(envName); err != nil {
+		return nil, err
+	}
 
-		for _, ns := range g.dotGalaxy.ListNamespaces() {
-			var baseDir string
+	d := NewDoctor(g.cfg, g.clients, env, g.Modified[envName], g.namespaces(envName),
+		g.namespaceSpecs(envName), g.dotGalaxy.Spec.Namespaces.BaseDir)
+	err = d.Run()
+	return d.Checks, err
+}
 
-			if baseDir, err = g.dotGalaxy.GetNamespaceDir(ns); err != nil {
-				return err
-			}
-			logger.Infof("Inspecting namespace '%s', directory '%s'", ns, baseDir)
-			if err = ctx.InspectDir(ns, baseDir, exts); err != nil {
-				logger.Fatalf("error during inspecting context: %#v", err)
-				return err
+// Import Helm releases running on target namespaces of a single environment, writing release files
+// on original namespace directories. Charts not found on repositories index in Helm home are
+// referred as repository name informed.
+func (g *Galaxy) Import(repoName string) ([]ImportedRelease, error) {
+	var envName string
+	var env *Environment
+	var helmClient *HelmClient
+	var imports []ImportedRelease
+	var err error
+
+	if envName, err = g.probeSingleEnv(); err != nil {
+		return nil, err
+	}
+	if env, err = g.dotGalaxy.GetEnvironment(envName); err != nil {
+		return nil, err
+	}
+	tlsCfg := env.Tiller.Merge(g.cfg.TillerTLSConfig).WithDefaults(g.cfg.HelmHome)
+	if helmClient, err = g.clients.Helm(g.cfg.KubeContext, tlsCfg); err != nil {
+		return nil, err
+	}
+
+	namespaces := g.cfg.GetNamespaces()
+	if len(namespaces) == 0 {
+		namespaces = g.dotGalaxy.ListNamespaces()
+	}
+	plan := NewPlan(env, namespaces, NewContext())
+	for _, ns := range namespaces {
+		var dir string
+		var declared []string
+
+		if plan.skipOnNamespace(ns) {
+			g.logger.Infof("Skipping namespace '%s' on environment '%s'", ns, envName)
+			continue
+		}
+		if dir, err = g.dotGalaxy.GetNamespaceDir(ns); err != nil {
+			return imports, err
+		}
+		targetNs := env.TargetNamespace(ns)
+		for _, ctx := range g.Modified[envName] {
+			for _, release := range ctx.Releases[targetNs] {
+				declared = append(declared, release.Component.Name)
 			}
 		}
 
+		i := NewImporter(helmClient.Client, g.cfg.HelmHome, env, ns, targetNs, dir, repoName,
+			declared, g.cfg.DryRun)
+		err = i.Import()
+		imports = append(imports, i.Imports...)
+		if err != nil {
+			return imports, err
+		}
+	}
+	return imports, nil
+}
+
+// Export planned releases of a single environment in another GitOps tool format, keeping renamed
+// namespaces and release names.
+func (g *Galaxy) Export(format string, argo ArgoCDSpec) ([]ExportedFile, error) {
+	var envName string
+	var env *Environment
+	var err error
+
+	if envName, err = g.probeSingleEnv(); err != nil {
+		return nil, err
+	}
+	if env, err = g.dotGalaxy.GetEnvironment(envName); err != nil {
+		return nil, err
+	}
+
+	e := NewExporter(env, g.Modified[envName], g.dotGalaxy.Spec.Charts.Repositories,
+		g.dotGalaxy.Spec.Namespaces.BaseDir, g.cfg.OverrideFile)
+	return e.Export(format, argo)
+}
+
+// FetchCharts add chart repositories declared on dot-galaxy to Helm home, and fetch charts employed
+// by releases on planned environments into local cache, failing when a chart version is not found.
+func (g *Galaxy) FetchCharts() ([]ChartFetch, error) {
+	var ctxs []*Context
+
+	for _, envCtxs := range g.Modified {
+		ctxs = append(ctxs, envCtxs...)
+	}
+
+	c := NewCharts(g.dotGalaxy.Spec.Namespaces.BaseDir, g.cfg.HelmHome,
+		g.dotGalaxy.Spec.Charts.Repositories)
+	if err := c.SyncRepositories(); err != nil {
+		return nil, err
+	}
+	err := c.Fetch(chartRefs(ctxs))
+	return c.Fetches, err
+}
+
+// OutdatedCharts add chart repositories declared on dot-galaxy to Helm home, and list releases on
+// planned environments whose chart version lags behind the latest version available.
+func (g *Galaxy) OutdatedCharts() ([]ChartOutdated, error) {
+	var envNames []string
+
+	for envName := range g.Modified {
+		envNames = append(envNames, envName)
+	}
+	sort.Strings(envNames)
+
+	c := NewCharts(g.dotGalaxy.Spec.Namespaces.BaseDir, g.cfg.HelmHome,
+		g.dotGalaxy.Spec.Charts.Repositories)
+	if err := c.SyncRepositories(); err != nil {
+		return nil, err
+	}
+	for _, envName := range envNames {
+		if err := c.FindOutdated(envName, g.Modified[envName]); err != nil {
+			return c.Outdated, err
+		}
+	}
+	return c.Outdated, nil
+}
+
+// Promote rewrite release files of target environment, copying chart and release version from the
+// same releases on source environment. Release name is optional.
+func (g *Galaxy) Promote(from, to, release string) ([]Promotion, error) {
+	var fromEnv, toEnv *Environment
+	var ctx *Context
+	var err error
+
+	if from == to {
+		return nil, fmt.Errorf("source and target environments must differ")
+	}
+	if fromEnv, err = g.dotGalaxy.GetEnvironment(from); err != nil {
+		return nil, err
+	}
+	if toEnv, err = g.dotGalaxy.GetEnvironment(to); err != nil {
+		return nil, err
+	}
+	if ctx, err = g.inspectNamespaces(g.logger); err != nil {
+		return nil, err
+	}
+
+	p := NewPromoter(fromEnv, toEnv, ctx, g.cfg.GetNamespaces(), release, g.cfg.DryRun)
+	err = p.Promote()
+	return p.Promotions, err
+}
+
+// namespaces planned for environment, sorted.
+func (g *Galaxy) namespaces(envName string) []string {
+	var namespaces []string
+
+	for ns := range g.envOriginalNs[envName] {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// namespaceSpecs dot-galaxy entries of namespaces in environment.
+func (g *Galaxy) namespaceSpecs(envName string) []*NamespaceSpec {
+	var specs []*NamespaceSpec
+
+	for _, originalNs := range g.envOriginalNs[envName] {
+		if spec, err := g.dotGalaxy.GetNamespaceSpec(originalNs); err == nil {
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
+// validateSecrets inspect secret source data for every namespace in environment, before apply,
+// following validation policy.
+func (g *Galaxy) validateSecrets(source SecretSource, envName string) error {
+	var issues []SecretIssue
+
+	logger := g.logger.WithFields(log.Fields{"env": envName, "policy": g.cfg.SecretsValidation})
+	switch g.cfg.SecretsValidation {
+	case SecretsValidationSkip:
+		logger.Info("Skipping secrets validation.")
+		return nil
+	case SecretsValidationFail, SecretsValidationWarn:
+	default:
+		return fmt.Errorf("unknown secrets validation policy '%s'", g.cfg.SecretsValidation)
+	}
+
+	v := NewSecretsValidator(source, g.Modified[envName])
+	for ns := range g.envOriginalNs[envName] {
+		nsIssues, err := v.Validate(ns)
+		if err != nil {
+			return err
+		}
+		issues = append(issues, nsIssues...)
+	}
+
+	for _, issue := range issues {
+		logger.Warn(issue.String())
+	}
+	if len(issues) > 0 && g.cfg.SecretsValidation == SecretsValidationFail {
+		return fmt.Errorf("secrets validation found %d issue(s)", len(issues))
+	}
+	return nil
+}
+
+// manifestHandler instantiate the Kubernetes manifests handler for environment. API resources are
+// only discovered when manifests or overlays are planned, or pruning is enabled.
+func (g *Galaxy) manifestHandler(envName string, kubeClient *KubeClient) (*ManifestHandler, error) {
+	var mapper meta.RESTMapper
+
+	planned := false
+	for _, ctx := range g.Modified[envName] {
+		if len(ctx.Manifests) > 0 || len(ctx.Overlays) > 0 {
+			planned = true
+		}
+	}
+	if planned || g.cfg.PruneManifests {
+		groupResources, err := restmapper.GetAPIGroupResources(kubeClient.Client.Discovery())
+		if err != nil {
+			return nil, err
+		}
+		mapper = restmapper.NewDiscoveryRESTMapper(groupResources)
+	}
+	return NewManifestHandler(kubeClient.Dynamic, mapper, envName,
+		g.dotGalaxy.Spec.Namespaces.BaseDir, g.Modified[envName], g.cfg.DryRun), nil
+}
+
+// secretsApplier instantiate the secrets handler for environment's secret source. Vault sources
+// are handled by vault-handler, using the shared Vault session, so authentication happens a single
+// time.
+func (g *Galaxy) secretsApplier(
+	env *Environment, ctxs []*Context, kubeClient *KubeClient) (SecretsApplier, error) {
+	var vaultClient *VaultClient
+	var source SecretSource
+	var err error
+
+	if env.Secrets.GetSource() == SecretSourceVault {
+		if vaultClient, err = g.clients.Vault(); err != nil {
+			return nil, err
+		}
+		return NewVaultHandler(g.cfg.VaultHandlerConfig, g.cfg.KubernetesConfig, vaultClient,
+			ctxs), nil
+	}
+
+	if source, err = g.secretSource(env); err != nil {
+		return nil, err
+	}
+	return NewSecretsHandler(source, kubeClient.Client.Core(), ctxs), nil
+}
+
+// secretSource instantiate the secret source configured for environment, Vault sources employ the
+// shared authenticated session.
+func (g *Galaxy) secretSource(env *Environment) (SecretSource, error) {
+	var vaultClient *VaultClient
+	var err error
+
+	if err = env.Secrets.Validate(); err != nil {
+		return nil, err
+	}
+
+	g.logger.Infof("Using secret source '%s'", env.Secrets.GetSource())
+	if env.Secrets.GetSource() == SecretSourceFile {
+		return NewFileSecretSource(
+			env.Secrets.Dir, env.Secrets.KeyRing, g.cfg.SecretsPassphrase), nil
+	}
+
+	if vaultClient, err = g.clients.Vault(); err != nil {
+		return nil, err
+	}
+	return vaultClient, nil
+}
+
+// SecretsStatus compares secret source contents with Kubernetes secrets for the planned
+// environment.
+func (g *Galaxy) SecretsStatus() ([]SecretKeyStatus, error) {
+	var envName string
+	var env *Environment
+	var source SecretSource
+	var statuses []SecretKeyStatus
+	var kubeClient *KubeClient
+	var err error
+
+	if envName, err = g.probeSingleEnv(); err != nil {
+		return nil, err
+	}
+
+	logger := g.logger.WithField("env", envName)
+	logger.Info("Inspecting secrets status for environment...")
+
+	if env, err = g.dotGalaxy.GetEnvironment(envName); err != nil {
+		return nil, err
+	}
+	if kubeClient, err = g.clients.Kube(g.cfg.KubeContext); err != nil {
+		return nil, err
+	}
+	if source, err = g.secretSource(env); err != nil {
+		return nil, err
+	}
+
+	s := NewSecretsStatus(source, kubeClient.Client.Core(), g.Modified[envName])
+	for ns := range g.envOriginalNs[envName] {
+		var nsStatuses []SecretKeyStatus
+
+		logger.Infof("Inspecting secrets on namespace '%s'", ns)
+		if nsStatuses, err = s.Inspect(ns); err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, nsStatuses...)
+	}
+	return statuses, nil
+}
+
+// Certificates inspect TLS secrets planned for environment, reading certificates from the secret
+// source, or from Kubernetes when fromCluster is set.
+func (g *Galaxy) Certificates(fromCluster bool) ([]Certificate, error) {
+	var envName string
+	var env *Environment
+	var c *Certificates
+	var certs []Certificate
+	var err error
+
+	if envName, err = g.probeSingleEnv(); err != nil {
+		return nil, err
+	}
+
+	logger := g.logger.WithFields(log.Fields{"env": envName, "fromCluster": fromCluster})
+	logger.Info("Inspecting certificates for environment...")
+
+	if fromCluster {
+		var kubeClient *KubeClient
+
+		if kubeClient, err = g.clients.Kube(g.cfg.KubeContext); err != nil {
+			return nil, err
+		}
+		c = NewCertificates(nil, kubeClient.Client.Core(), g.Modified[envName])
+	} else {
+		var source SecretSource
+
+		if env, err = g.dotGalaxy.GetEnvironment(envName); err != nil {
+			return nil, err
+		}
+		if source, err = g.secretSource(env); err != nil {
+			return nil, err
+		}
+		c = NewCertificates(source, nil, g.Modified[envName])
+	}
+
+	for ns := range g.envOriginalNs[envName] {
+		var nsCerts []Certificate
+
+		if nsCerts, err = c.Inspect(ns); err != nil {
+			return nil, err
+		}
+		certs = append(certs, nsCerts...)
+	}
+	return certs, nil
+}
+
+// Close shared clients, including tunnels to Tiller.
+func (g *Galaxy) Close() {
+	g.clients.Close()
+}
+
+// Loop over environments and its contexts.
+func (g *Galaxy) Loop(fn actOnContext) error {
+	var ctx *Context
+	var err error
+
+	for _, env := range g.dotGalaxy.ListEnvironments() {
+		logger := g.logger.WithField("env", env)
+
+		if ctx, err = g.inspectNamespaces(logger); err != nil {
+			return err
+		}
 		if err = fn(logger, env, ctx); err != nil {
 			return err
 		}
@@ -143,6 +609,28 @@ func (g *Galaxy) Loop(fn actOnContext) error {
 	return nil
 }
 
+// inspectNamespaces creates a new context, inspecting the directory of every namespace.
+func (g *Galaxy) inspectNamespaces(logger *log.Entry) (*Context, error) {
+	var exts = g.dotGalaxy.Spec.Namespaces.Extensions
+	var err error
+
+	ctx := NewContext()
+	ctx.FileKinds = g.dotGalaxy.Spec.Namespaces.FileKinds
+	for _, ns := range g.dotGalaxy.ListNamespaces() {
+		var baseDir string
+
+		if baseDir, err = g.dotGalaxy.GetNamespaceDir(ns); err != nil {
+			return nil, err
+		}
+		logger.Infof("Inspecting namespace '%s', directory '%s'", ns, baseDir)
+		if err = ctx.InspectDir(ns, baseDir, exts); err != nil {
+			logger.Fatalf("error during inspecting context: %#v", err)
+			return nil, err
+		}
+	}
+	return ctx, nil
+}
+
 // probeSingleEnv make sure a single environment is informed, and it's present in planned data, also
 // original name is able to be found.
 func (g *Galaxy) probeSingleEnv() (string, error) {
@@ -173,5 +661,6 @@ func NewGalaxy(dotGalaxy *DotGalaxy, cfg *Config) *Galaxy {
 		original:      make(Data),
 		Modified:      make(Data),
 		envOriginalNs: make(map[string]map[string]string),
+		clients:       NewClients(cfg.KubernetesConfig, cfg.LandscaperConfig, cfg.VaultHandlerConfig),
 	}
 }
@@ -1,6 +1,7 @@
 package galaxy
 
 import (
+	"crypto/tls"
 	"fmt"
 	"os"
 
@@ -9,6 +10,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/helm/pkg/helm"
 	helmkube "k8s.io/helm/pkg/kube"
+	"k8s.io/helm/pkg/tlsutil"
 	helmversion "k8s.io/helm/pkg/version"
 	podutil "k8s.io/kubernetes/pkg/api/pod"
 	core "k8s.io/kubernetes/pkg/apis/core"
@@ -16,13 +18,15 @@ import (
 
 // HelmClient is wrapper for loading a Helm API client instance.
 type HelmClient struct {
-	logger     *log.Entry     // logger
-	Client     helm.Interface // helm client
-	home       string         // helm home directory
-	ns         string         // namespace name
-	port       int            // tiller port number
-	timeout    int64          // tiller timeout
-	kubeClient *KubeClient    // kubernetes client
+	logger     *log.Entry       // logger
+	Client     helm.Interface   // helm client
+	home       string           // helm home directory
+	ns         string           // namespace name
+	port       int              // tiller port number
+	timeout    int64            // tiller timeout
+	tlsCfg     *TillerTLSConfig // tiller tls configuration, optional
+	kubeClient *KubeClient      // kubernetes client
+	tunnel     *helmkube.Tunnel // port-forward tunnel to tiller
 }
 
 // Load configuration and instantiate client.
@@ -36,8 +40,20 @@ func (h *HelmClient) Load() error {
 		return err
 	}
 
+	options := []helm.Option{helm.Host(address), helm.ConnectTimeout(h.timeout)}
+	if h.tlsCfg != nil && h.tlsCfg.Enabled() {
+		var tlsCfg *tls.Config
+
+		h.logger.Infof("Using TLS to reach Tiller (verify '%v', ca '%s', cert '%s', key '%s')",
+			h.tlsCfg.TLSVerify, h.tlsCfg.TLSCaCert, h.tlsCfg.TLSCert, h.tlsCfg.TLSKey)
+		if tlsCfg, err = h.tlsConfig(); err != nil {
+			return err
+		}
+		options = append(options, helm.WithTLS(tlsCfg))
+	}
+
 	h.logger.Infof("Connecting to Helm via '%s' (timeout %d seconds)", address, h.timeout)
-	h.Client = helm.NewClient(helm.Host(address), helm.ConnectTimeout(h.timeout))
+	h.Client = helm.NewClient(options...)
 	if err = h.Client.PingTiller(); err != nil {
 		return err
 	}
@@ -56,6 +72,22 @@ func (h *HelmClient) Load() error {
 	return nil
 }
 
+// tlsConfig creates TLS configuration based on certificate files, Tiller certificate is only
+// verified against CA when verify is enabled, as helm command-line does.
+func (h *HelmClient) tlsConfig() (*tls.Config, error) {
+	opts := tlsutil.Options{
+		ServerName:         h.tlsCfg.TLSServerName,
+		CertFile:           h.tlsCfg.TLSCert,
+		KeyFile:            h.tlsCfg.TLSKey,
+		InsecureSkipVerify: true,
+	}
+	if h.tlsCfg.TLSVerify {
+		opts.CaCertFile = h.tlsCfg.TLSCaCert
+		opts.InsecureSkipVerify = false
+	}
+	return tlsutil.ClientConfig(opts)
+}
+
 // getHelmTillerAddress inspect environment for Helm hostname, or establish a port-forward to tiller.
 func (h *HelmClient) getHelmTillerAddress() (string, error) {
 	var podName string
@@ -75,13 +107,22 @@ func (h *HelmClient) getHelmTillerAddress() (string, error) {
 	h.logger.Debugf("Tiller pod name '%s'", podName)
 
 	restClient := h.kubeClient.Client.Core().RESTClient()
-	tunnel := helmkube.NewTunnel(restClient, h.kubeClient.RestCfg, h.ns, podName, h.port)
+	h.tunnel = helmkube.NewTunnel(restClient, h.kubeClient.RestCfg, h.ns, podName, h.port)
 
-	if err = tunnel.ForwardPort(); err != nil {
+	if err = h.tunnel.ForwardPort(); err != nil {
 		return "", err
 	}
 
-	return fmt.Sprintf(":%d", tunnel.Local), nil
+	return fmt.Sprintf(":%d", h.tunnel.Local), nil
+}
+
+// Close port-forward tunnel to tiller, when in use.
+func (h *HelmClient) Close() {
+	if h.tunnel != nil {
+		h.logger.Info("Closing tunnel to Tiller...")
+		h.tunnel.Close()
+		h.tunnel = nil
+	}
 }
 
 // getHelmTillerPodName using Kubernetes API client, look for Tiller's pod.
@@ -108,8 +149,10 @@ func (h *HelmClient) getHelmTillerPodName() (string, error) {
 	return "", fmt.Errorf("can't find a ready tiller pod on '%s' namespace", h.ns)
 }
 
-// NewHelmClient new type instance.
-func NewHelmClient(home, ns string, port int, timeout int64, kubeClient *KubeClient) *HelmClient {
+// NewHelmClient new type instance, TLS configuration is optional.
+func NewHelmClient(
+	home, ns string, port int, timeout int64, tlsCfg *TillerTLSConfig, kubeClient *KubeClient,
+) *HelmClient {
 	return &HelmClient{
 		logger: log.WithFields(log.Fields{
 			"type":      "helmClient",
@@ -122,6 +165,7 @@ func NewHelmClient(home, ns string, port int, timeout int64, kubeClient *KubeCli
 		ns:         ns,
 		port:       port,
 		timeout:    timeout,
+		tlsCfg:     tlsCfg,
 		kubeClient: kubeClient,
 	}
 }
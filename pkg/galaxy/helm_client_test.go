@@ -12,7 +12,8 @@ func TestHelmClientNew(t *testing.T) {
 	cfg := NewConfig()
 	k := NewKubeClient(cfg.KubernetesConfig)
 	_ = k.Load()
-	helmClient = NewHelmClient(cfg.HelmHome, cfg.TillerNamespace, cfg.TillerPort, cfg.TillerTimeout, k)
+	helmClient = NewHelmClient(
+		cfg.HelmHome, cfg.TillerNamespace, cfg.TillerPort, cfg.TillerTimeout, nil, k)
 }
 
 func TestHelmClientLoad(t *testing.T) {
@@ -0,0 +1,285 @@
+package galaxy
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	ldsc "github.com/Eneco/landscaper/pkg/landscaper"
+	ghodssyaml "github.com/ghodss/yaml"
+	"github.com/ryanuber/columnize"
+	log "github.com/sirupsen/logrus"
+	yaml "gopkg.in/yaml.v2"
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/helm/helmpath"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/proto/hapi/services"
+	"k8s.io/helm/pkg/repo"
+)
+
+const (
+	// ImportCreated release file has been written
+	ImportCreated = "imported"
+	// ImportExists release is already declared in repository, or release file exists
+	ImportExists = "exists"
+	// ImportDryRun release file would be written, on dry-run
+	ImportDryRun = "dry-run"
+	// ImportSkipped release name is lacking environment release prefix
+	ImportSkipped = "skipped"
+)
+
+// releasesPageSize amount of releases requested per page, when listing Helm releases.
+const releasesPageSize = 256
+
+const (
+	// landscaperMetadataKey configuration key where Landscaper keeps chart repository and release
+	// version, on releases it has deployed.
+	landscaperMetadataKey = "_landscaper_metadata"
+	// landscaperSecretsRefKey configuration key injected by Landscaper on releases with secrets.
+	landscaperSecretsRefKey = "secretsRef"
+)
+
+// ImportedRelease outcome of importing a single Helm release.
+type ImportedRelease struct {
+	Namespace string // original namespace
+	Release   string // helm release name
+	Name      string // component name, without release prefix
+	Chart     string // chart reference, as in "repo/name:version"
+	File      string // release file path
+	Status    string // imported, exists, dry-run or skipped
+}
+
+// Importer generates Landscaper component files from Helm releases running on environment target
+// namespace, reversing environment transformations, so existing releases are adopted by Galaxy.
+type Importer struct {
+	logger   *log.Entry        // logger
+	client   helm.Interface    // helm client
+	home     helmpath.Home     // helm home, for repositories index
+	env      *Environment      // environment instance
+	ns       string            // original namespace name
+	targetNs string            // target namespace name
+	dir      string            // original namespace directory
+	repoName string            // chart repository used when not found otherwise
+	declared []string          // release names already declared on repository
+	dryRun   bool              // dry-run flag
+	Imports  []ImportedRelease // releases imported
+}
+
+// Import deployed Helm releases on target namespace, writing a release file per release on the
+// original namespace directory. Releases already declared, or with existing files, are skipped, as
+// well as releases lacking environment release prefix.
+func (i *Importer) Import() error {
+	var releases []*release.Release
+	var prefix string
+	var err error
+
+	if prefix, err = i.env.Interpolate(i.env.Transform.ReleasePrefix, []string{
+		fmt.Sprintf("NAMESPACE=%s", i.ns),
+	}); err != nil {
+		return err
+	}
+
+	i.logger.Info("Listing Helm releases...")
+	if releases, err = i.listReleases(); err != nil {
+		return err
+	}
+	sort.Slice(releases, func(a, b int) bool {
+		return releases[a].GetName() < releases[b].GetName()
+	})
+
+	for _, rls := range releases {
+		if rls.GetNamespace() != i.targetNs {
+			continue
+		}
+		if err = i.importRelease(rls, prefix); err != nil {
+			return fmt.Errorf("release '%s': %s", rls.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// listReleases deployed Helm releases on target namespace, requesting all pages.
+func (i *Importer) listReleases() ([]*release.Release, error) {
+	var releases []*release.Release
+	var offset string
+
+	for {
+		var resp *services.ListReleasesResponse
+		var err error
+
+		if resp, err = i.client.ListReleases(
+			helm.ReleaseListNamespace(i.targetNs),
+			helm.ReleaseListStatuses([]release.Status_Code{release.Status_DEPLOYED}),
+			helm.ReleaseListLimit(releasesPageSize),
+			helm.ReleaseListOffset(offset),
+		); err != nil {
+			return nil, err
+		}
+		releases = append(releases, resp.GetReleases()...)
+
+		// next page starts on release name informed, when any
+		if resp.GetNext() == "" || resp.GetNext() == offset {
+			break
+		}
+		offset = resp.GetNext()
+	}
+	return releases, nil
+}
+
+// importRelease write release file for a single Helm release.
+func (i *Importer) importRelease(rls *release.Release, prefix string) error {
+	var cfg ldsc.Configuration
+	var repoName, version string
+	var payload []byte
+	var err error
+
+	name := strings.TrimPrefix(rls.GetName(), prefix)
+	logger := i.logger.WithFields(log.Fields{"release": rls.GetName(), "name": name})
+
+	if !strings.HasPrefix(rls.GetName(), prefix) {
+		logger.Warnf("Release is lacking environment release prefix '%s', skipping.", prefix)
+		i.Imports = append(i.Imports, ImportedRelease{
+			Namespace: i.ns, Release: rls.GetName(), Name: name, Status: ImportSkipped,
+		})
+		return nil
+	}
+
+	if err = ghodssyaml.Unmarshal([]byte(rls.GetConfig().GetRaw()), &cfg); err != nil {
+		return err
+	}
+	if cfg == nil {
+		cfg = ldsc.Configuration{}
+	}
+
+	// releases deployed by Landscaper carry chart repository and release version
+	if cfg.HasMetadata() {
+		if m, err := cfg.GetMetadata(); err == nil {
+			repoName, version = m.ChartRepository, m.ReleaseVersion
+		}
+		delete(cfg, landscaperMetadataKey)
+	}
+	delete(cfg, landscaperSecretsRefKey)
+	metadata := rls.GetChart().GetMetadata()
+	if repoName == "" {
+		repoName = i.chartRepo(metadata.GetName(), metadata.GetVersion())
+	}
+	if version == "" {
+		version = "0.0.1"
+	}
+
+	imported := ImportedRelease{
+		Namespace: i.ns,
+		Release:   rls.GetName(),
+		Name:      name,
+		Chart:     fmt.Sprintf("%s/%s:%s", repoName, metadata.GetName(), metadata.GetVersion()),
+		File:      path.Join(i.dir, i.fileName(name)),
+		Status:    ImportCreated,
+	}
+
+	if stringSliceContains(i.declared, rls.GetName()) || fileExists(imported.File) {
+		logger.Info("Release is already declared in repository.")
+		imported.Status = ImportExists
+		i.Imports = append(i.Imports, imported)
+		return nil
+	}
+
+	if payload, err = yaml.Marshal(yaml.MapSlice{
+		{Key: "name", Value: name},
+		{Key: "release", Value: yaml.MapSlice{
+			{Key: "chart", Value: imported.Chart},
+			{Key: "version", Value: version},
+		}},
+		{Key: "configuration", Value: map[string]interface{}(cfg)},
+	}); err != nil {
+		return err
+	}
+	payload = append([]byte("---\n"), payload...)
+
+	if i.dryRun {
+		logger.Infof("DRY-RUN: Release file would be written at '%s'", imported.File)
+		imported.Status = ImportDryRun
+		i.Imports = append(i.Imports, imported)
+		return nil
+	}
+	logger.Infof("Writing release file '%s'", imported.File)
+	if err = os.MkdirAll(i.dir, 0755); err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(imported.File, payload, 0644); err != nil {
+		return err
+	}
+	i.Imports = append(i.Imports, imported)
+	return nil
+}
+
+// chartRepo look for chart name and version on repositories index in Helm home, returning the
+// repository name. When not found, the default repository name is returned.
+func (i *Importer) chartRepo(name, version string) string {
+	repoFile, err := repo.LoadRepositoriesFile(i.home.RepositoryFile())
+	if err != nil {
+		i.logger.Debugf("Unable to load repositories file: %s", err)
+		return i.repoName
+	}
+	for _, entry := range repoFile.Repositories {
+		index, err := repo.LoadIndexFile(i.home.CacheIndex(entry.Name))
+		if err != nil {
+			continue
+		}
+		if _, err = index.Get(name, version); err == nil {
+			return entry.Name
+		}
+	}
+	i.logger.Warnf("Chart '%s:%s' is not found on repositories index, using '%s'",
+		name, version, i.repoName)
+	return i.repoName
+}
+
+// fileName release file name, using the first file suffix of environment, when present.
+func (i *Importer) fileName(name string) string {
+	for _, suffix := range i.env.FileSuffixes {
+		if suffix != "" {
+			return fmt.Sprintf("%s@%s.yaml", name, suffix)
+		}
+	}
+	return fmt.Sprintf("%s.yaml", name)
+}
+
+// ImportsTable format imported releases as a table.
+func ImportsTable(imports []ImportedRelease) string {
+	lines := []string{"NAMESPACE | RELEASE | CHART | STATUS | FILE"}
+	for _, i := range imports {
+		lines = append(lines, fmt.Sprintf("%s | %s | %s | %s | %s",
+			i.Namespace, i.Release, i.Chart, i.Status, i.File))
+	}
+	return columnize.SimpleFormat(lines)
+}
+
+// NewImporter instantiate importer of Helm releases on target namespace, into the original
+// namespace directory. Declared are the release names already planned for target namespace.
+func NewImporter(
+	client helm.Interface,
+	helmHome string,
+	env *Environment,
+	ns, targetNs, dir, repoName string,
+	declared []string,
+	dryRun bool,
+) *Importer {
+	return &Importer{
+		logger: log.WithFields(log.Fields{
+			"type": "importer", "env": env.Name, "namespace": targetNs, "dryRun": dryRun,
+		}),
+		client:   client,
+		home:     helmpath.Home(helmHome),
+		env:      env,
+		ns:       ns,
+		targetNs: targetNs,
+		dir:      dir,
+		repoName: repoName,
+		declared: declared,
+		dryRun:   dryRun,
+		Imports:  []ImportedRelease{},
+	}
+}
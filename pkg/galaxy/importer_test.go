@@ -0,0 +1,130 @@
+package galaxy
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/proto/hapi/services"
+)
+
+// pagedHelmClient fake helm client returning a page of releases per call, informing the next
+// release name while there are pages left.
+type pagedHelmClient struct {
+	helm.FakeClient
+	pages [][]*release.Release
+}
+
+// ListReleases next page of releases.
+func (p *pagedHelmClient) ListReleases(opts ...helm.ReleaseListOption) (
+	*services.ListReleasesResponse, error) {
+	resp := &services.ListReleasesResponse{Releases: p.pages[0]}
+	if p.pages = p.pages[1:]; len(p.pages) > 0 {
+		resp.Next = p.pages[0][0].GetName()
+	}
+	return resp, nil
+}
+
+// fakeHelmRelease deployed helm release, with chart "app" and informed values.
+func fakeHelmRelease(name, ns, values string) *release.Release {
+	return &release.Release{
+		Name:      name,
+		Namespace: ns,
+		Chart: &chart.Chart{
+			Metadata: &chart.Metadata{Name: "app", Version: "0.1.0"},
+		},
+		Config: &chart.Config{Raw: values},
+		Info:   &release.Info{Status: &release.Status{Code: release.Status_DEPLOYED}},
+	}
+}
+
+func TestImporterImport(t *testing.T) {
+	SetLogLevel("trace")
+
+	dir, err := ioutil.TempDir("", "galaxy-importer")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	env := &Environment{
+		Name:         "dev",
+		FileSuffixes: []string{"", "d"},
+		Transform: Transform{
+			NamespaceSuffix: "-d",
+			ReleasePrefix:   "${NAMESPACE_SUFFIX:1}-${NAMESPACE}-",
+		},
+	}
+	client := &helm.FakeClient{Rels: []*release.Release{
+		fakeHelmRelease("d-ns1-web", "ns1-d", "replicas: 2\nsecretsRef: d-ns1-web\n"+
+			"_landscaper_metadata:\n  chartrepository: internal\n  releaseversion: 1.2.3\n"),
+		fakeHelmRelease("d-ns1-api", "ns1-d", "replicas: 1\n"),
+		fakeHelmRelease("d-ns1-declared", "ns1-d", ""),
+		fakeHelmRelease("unprefixed", "ns1-d", ""),
+		fakeHelmRelease("d-ns2-other", "ns2-d", ""),
+	}}
+
+	i := NewImporter(client, dir, env, "ns1", env.TargetNamespace("ns1"), dir, "stable",
+		[]string{"d-ns1-declared"}, false)
+	assert.Nil(t, i.Import())
+	assert.Len(t, i.Imports, 4)
+
+	statuses := map[string]string{}
+	for _, imported := range i.Imports {
+		statuses[imported.Name] = imported.Status
+	}
+	assert.Equal(t, map[string]string{
+		"api":        ImportCreated,
+		"declared":   ImportExists,
+		"unprefixed": ImportSkipped,
+		"web":        ImportCreated,
+	}, statuses)
+
+	ctx := NewContext()
+	assert.Nil(t, ctx.InspectDir("ns1", dir, []string{"yaml"}))
+	assert.Len(t, ctx.Releases["ns1"], 2)
+	for _, r := range ctx.Releases["ns1"] {
+		assert.Contains(t, r.File, "@d.yaml")
+		assert.NotContains(t, r.Component.Configuration, landscaperMetadataKey)
+		assert.NotContains(t, r.Component.Configuration, landscaperSecretsRefKey)
+		switch r.Component.Name {
+		case "web":
+			assert.Equal(t, "internal/app:0.1.0", r.Component.Release.Chart)
+			assert.Equal(t, "1.2.3", r.Component.Release.Version)
+			assert.EqualValues(t, 2, r.Component.Configuration["replicas"])
+		case "api":
+			assert.Equal(t, "stable/app:0.1.0", r.Component.Release.Chart)
+			assert.Equal(t, "0.0.1", r.Component.Release.Version)
+		}
+	}
+
+	// on dry-run files are not written
+	i = NewImporter(client, dir, env, "ns1", env.TargetNamespace("ns1"), path.Join(dir, "ns"),
+		"stable", []string{}, true)
+	assert.Nil(t, i.Import())
+	assert.Equal(t, ImportDryRun, i.Imports[0].Status)
+	assert.False(t, isDir(path.Join(dir, "ns")))
+}
+
+func TestImporterListReleasesPages(t *testing.T) {
+	client := &pagedHelmClient{}
+	for page := 0; page < 3; page++ {
+		var releases []*release.Release
+		for i := 0; i < 2; i++ {
+			releases = append(releases,
+				fakeHelmRelease(fmt.Sprintf("d-ns1-app%d%d", page, i), "ns1-d", ""))
+		}
+		client.pages = append(client.pages, releases)
+	}
+
+	env := &Environment{Name: "dev", Transform: Transform{NamespaceSuffix: "-d"}}
+	i := NewImporter(client, "", env, "ns1", "ns1-d", "", "stable", []string{}, true)
+	releases, err := i.listReleases()
+	assert.Nil(t, err)
+	assert.Len(t, releases, 6)
+	assert.Equal(t, "d-ns1-app21", releases[5].GetName())
+}
@@ -2,22 +2,35 @@ package galaxy
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 
+	ghodssyaml "github.com/ghodss/yaml"
 	log "github.com/sirupsen/logrus"
-	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp" // gcp auth
+	"k8s.io/client-go/dynamic"
+	_ "k8s.io/client-go/plugin/pkg/client/auth/azure" // azure auth
+	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"   // gcp auth
+	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"  // oidc auth
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	clientcmdlatest "k8s.io/client-go/tools/clientcmd/api/latest"
+	clientcmdv1 "k8s.io/client-go/tools/clientcmd/api/v1"
 	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset"
 )
 
-// KubeClient wrapper for Kubernetes API client.
+// explicitContextName context name on kube-config generated for informed API server and token.
+const explicitContextName = "galaxy"
+
+// KubeClient wrapper for Kubernetes API client. Besides kube-config auth-providers, exec based
+// credential plugins are supported by client-go, as informed in kube-config "users" section.
 type KubeClient struct {
 	logger  *log.Entry           // logger
 	cfg     *KubernetesConfig    // configuration parameters
 	RestCfg *rest.Config         // kubernetes rest config
 	Client  *clientset.Clientset // kubernetes clientset
+	Dynamic dynamic.Interface    // kubernetes dynamic client, for arbitrary objects
 }
 
 // Load the new Kubernetes API client.
@@ -29,6 +42,11 @@ func (k *KubeClient) Load() error {
 		if k.RestCfg, err = rest.InClusterConfig(); err != nil {
 			return err
 		}
+	} else if k.cfg.KubeServer != "" {
+		k.logger.Infof("Using Kubernetes API server '%s' and token...", k.cfg.KubeServer)
+		if k.RestCfg, err = k.getExplicitRestConfig(); err != nil {
+			return err
+		}
 	} else {
 		k.logger.Info("Using local kube-config...")
 		if k.RestCfg, err = k.getKubeRestConfig(); err != nil {
@@ -39,6 +57,9 @@ func (k *KubeClient) Load() error {
 	if k.Client, err = clientset.NewForConfig(k.RestCfg); err != nil {
 		return err
 	}
+	if k.Dynamic, err = dynamic.NewForConfig(k.RestCfg); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -57,7 +78,91 @@ func (k *KubeClient) getKubeRestConfig() (*rest.Config, error) {
 		return nil, fmt.Errorf("can't find kube-config file at: '%s'", k.cfg.KubeConfig)
 	}
 
-	return clientcmd.BuildConfigFromFlags(k.cfg.KubeContext, k.cfg.KubeConfig)
+	restCfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: k.cfg.KubeConfig},
+		&clientcmd.ConfigOverrides{CurrentContext: k.cfg.KubeContext},
+	).ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// overrides on user credentials are not applied when kube-config has a value already, so token
+	// replaces credentials on the final configuration instead
+	if k.cfg.KubeToken != "" {
+		k.logger.Info("Using informed token instead of kube-config credentials")
+		restCfg.BearerToken = k.cfg.KubeToken
+		restCfg.Username = ""
+		restCfg.Password = ""
+		restCfg.AuthProvider = nil
+		restCfg.ExecProvider = nil
+		restCfg.TLSClientConfig.CertFile = ""
+		restCfg.TLSClientConfig.CertData = nil
+		restCfg.TLSClientConfig.KeyFile = ""
+		restCfg.TLSClientConfig.KeyData = nil
+	}
+	return restCfg, nil
+}
+
+// getExplicitRestConfig creates REST client config based on informed API server, token and CA
+// certificate, without kube-config.
+func (k *KubeClient) getExplicitRestConfig() (*rest.Config, error) {
+	if k.cfg.KubeToken == "" {
+		return nil, fmt.Errorf("token is required when Kubernetes API server is informed")
+	}
+	if k.cfg.KubeCaCert != "" && !fileExists(k.cfg.KubeCaCert) {
+		return nil, fmt.Errorf("can't find Kubernetes CA certificate at: '%s'", k.cfg.KubeCaCert)
+	}
+
+	return clientcmd.NewDefaultClientConfig(
+		*explicitKubeConfig(k.cfg), &clientcmd.ConfigOverrides{}).ClientConfig()
+}
+
+// explicitKubeConfig kube-config holding informed API server, token and CA certificate, as a single
+// context.
+func explicitKubeConfig(cfg *KubernetesConfig) *clientcmdapi.Config {
+	config := clientcmdapi.NewConfig()
+	config.Clusters[explicitContextName] = &clientcmdapi.Cluster{
+		Server:               cfg.KubeServer,
+		CertificateAuthority: cfg.KubeCaCert,
+	}
+	config.AuthInfos[explicitContextName] = &clientcmdapi.AuthInfo{Token: cfg.KubeToken}
+	config.Contexts[explicitContextName] = &clientcmdapi.Context{
+		Cluster:  explicitContextName,
+		AuthInfo: explicitContextName,
+	}
+	config.CurrentContext = explicitContextName
+	return config
+}
+
+// writeExplicitKubeConfig write kube-config of informed API server and token on a temporary file,
+// readable only by the current user, for clients that only take a kube-config path.
+func writeExplicitKubeConfig(cfg *KubernetesConfig) (string, error) {
+	var config clientcmdv1.Config
+	var file *os.File
+	var payload []byte
+	var err error
+
+	if cfg.KubeToken == "" {
+		return "", fmt.Errorf("token is required when Kubernetes API server is informed")
+	}
+	if err = clientcmdlatest.Scheme.Convert(explicitKubeConfig(cfg), &config, nil); err != nil {
+		return "", err
+	}
+	config.APIVersion, config.Kind = "v1", "Config"
+	if payload, err = ghodssyaml.Marshal(config); err != nil {
+		return "", err
+	}
+
+	// temporary files are created readable only by the current user
+	if file, err = ioutil.TempFile("", "galaxy-kube-config"); err != nil {
+		return "", err
+	}
+	defer file.Close()
+	if _, err = file.Write(payload); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
 }
 
 // NewKubeClient instantiate a new Kubernetes API client.
@@ -68,6 +173,7 @@ func NewKubeClient(cfg *KubernetesConfig) *KubeClient {
 			"kubeConfig":  cfg.KubeConfig,
 			"kubeContext": cfg.KubeContext,
 			"inCluster":   cfg.InCluster,
+			"kubeServer":  cfg.KubeServer,
 		}),
 		cfg: cfg,
 	}
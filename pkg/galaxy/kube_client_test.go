@@ -1,6 +1,9 @@
 package galaxy
 
 import (
+	"io/ioutil"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -18,3 +21,60 @@ func TestKubeClientLoad(t *testing.T) {
 	assert.Nil(t, err)
 	assert.NotNil(t, kubeClient.Client)
 }
+
+func TestKubeClientLoadExplicit(t *testing.T) {
+	cfg := NewConfig()
+	cfg.KubeServer = "https://127.0.0.1:6443"
+	k := NewKubeClient(cfg.KubernetesConfig)
+	assert.NotNil(t, k.Load())
+
+	cfg.KubeToken = "token"
+	err := k.Load()
+	assert.Nil(t, err)
+	assert.Equal(t, "https://127.0.0.1:6443", k.RestCfg.Host)
+	assert.Equal(t, "token", k.RestCfg.BearerToken)
+
+	// clients taking only a kube-config path read the same server and token
+	kubeConfig, err := writeExplicitKubeConfig(cfg.KubernetesConfig)
+	assert.Nil(t, err)
+	defer os.Remove(kubeConfig)
+	info, err := os.Stat(kubeConfig)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	cfg.KubeServer = ""
+	cfg.KubeConfig = kubeConfig
+	cfg.KubeContext = explicitContextName
+	err = k.Load()
+	assert.Nil(t, err)
+	assert.Equal(t, "https://127.0.0.1:6443", k.RestCfg.Host)
+	assert.Equal(t, "token", k.RestCfg.BearerToken)
+}
+
+func TestKubeClientLoadTokenOverride(t *testing.T) {
+	kubeConfig := fakeKubeConfig(t)
+	defer os.Remove(kubeConfig)
+
+	cfg := NewConfig()
+	cfg.KubeConfig = kubeConfig
+	cfg.KubeToken = "override"
+	k := NewKubeClient(cfg.KubernetesConfig)
+
+	err := k.Load()
+	assert.Nil(t, err)
+	assert.Equal(t, "https://127.0.0.1:6443", k.RestCfg.Host)
+	assert.Equal(t, "override", k.RestCfg.BearerToken)
+
+	// client certificate from kube-config is not employed together with token
+	payload, err := ioutil.ReadFile(kubeConfig)
+	assert.Nil(t, err)
+	payload = []byte(strings.Replace(string(payload), "      token: token\n",
+		"      client-certificate-data: Y2VydA==\n      client-key-data: a2V5\n", 1))
+	assert.Nil(t, ioutil.WriteFile(kubeConfig, payload, 0600))
+
+	k = NewKubeClient(cfg.KubernetesConfig)
+	assert.Nil(t, k.Load())
+	assert.Equal(t, "override", k.RestCfg.BearerToken)
+	assert.Empty(t, k.RestCfg.TLSClientConfig.CertData)
+	assert.Empty(t, k.RestCfg.TLSClientConfig.KeyData)
+}
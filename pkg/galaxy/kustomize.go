@@ -0,0 +1,212 @@
+package galaxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// KustomizationFile file name identifying a kustomize overlay directory.
+const KustomizationFile = "kustomization.yaml"
+
+// selectorKinds workload kinds having label selector and pod template, where common labels are
+// added as well.
+var selectorKinds = []string{"Deployment", "StatefulSet", "DaemonSet", "ReplicaSet"}
+
+// Kustomization subset of kustomize's kustomization file supported by Galaxy.
+type Kustomization struct {
+	APIVersion            string            `yaml:"apiVersion"`            // optional api version
+	Kind                  string            `yaml:"kind"`                  // optional kind
+	Resources             []string          `yaml:"resources"`             // manifest files or dirs
+	Bases                 []string          `yaml:"bases"`                 // base directories
+	NamePrefix            string            `yaml:"namePrefix"`            // object name prefix
+	NameSuffix            string            `yaml:"nameSuffix"`            // object name suffix
+	Namespace             string            `yaml:"namespace"`             // replaced by target ns
+	CommonLabels          map[string]string `yaml:"commonLabels"`          // labels on all objects
+	CommonAnnotations     map[string]string `yaml:"commonAnnotations"`     // annotations on all
+	PatchesStrategicMerge []string          `yaml:"patchesStrategicMerge"` // patch files
+}
+
+// isKustomization checks if directory contains a kustomization file.
+func isKustomization(dirPath string) bool {
+	return fileExists(path.Join(dirPath, KustomizationFile))
+}
+
+// BuildKustomization build kustomize overlay directory in-process, returning the resulting objects.
+// Bases are built first, then patches are applied, and lastly names, labels and annotations are
+// transformed. Namespace is not set, objects are applied on the target namespace.
+func BuildKustomization(dirPath string) ([]*unstructured.Unstructured, error) {
+	return buildKustomization(dirPath, []string{})
+}
+
+// buildKustomization build informed directory, tracking directories visited to avoid cycles.
+func buildKustomization(dirPath string, visited []string) ([]*unstructured.Unstructured, error) {
+	var k *Kustomization
+	var objs []*unstructured.Unstructured
+	var absPath string
+	var payload []byte
+	var err error
+
+	if absPath, err = filepath.Abs(dirPath); err != nil {
+		return nil, err
+	}
+	if stringSliceContains(visited, absPath) {
+		return nil, fmt.Errorf("cycle on kustomization bases, at '%s'", dirPath)
+	}
+	visited = append(visited, absPath)
+
+	if payload, err = ioutil.ReadFile(path.Join(dirPath, KustomizationFile)); err != nil {
+		return nil, err
+	}
+	if err = yaml.UnmarshalStrict(payload, &k); err != nil {
+		return nil, fmt.Errorf("kustomization '%s': %s", dirPath, err)
+	}
+	if k == nil {
+		return nil, fmt.Errorf("kustomization '%s' is empty", dirPath)
+	}
+
+	for _, entry := range append(k.Bases, k.Resources...) {
+		var entryObjs []*unstructured.Unstructured
+
+		entryPath := path.Join(dirPath, entry)
+		if isDir(entryPath) {
+			entryObjs, err = buildKustomization(entryPath, visited)
+		} else {
+			entryObjs, err = readManifest(entryPath)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range entryObjs {
+			if findObject(objs, obj) != nil {
+				return nil, fmt.Errorf("kustomization '%s': duplicated %s '%s'",
+					dirPath, obj.GetKind(), obj.GetName())
+			}
+			objs = append(objs, obj)
+		}
+	}
+
+	for _, file := range k.PatchesStrategicMerge {
+		var patches []*unstructured.Unstructured
+
+		if patches, err = readManifest(path.Join(dirPath, file)); err != nil {
+			return nil, err
+		}
+		for _, patch := range patches {
+			target := findObject(objs, patch)
+			if target == nil {
+				return nil, fmt.Errorf("patch '%s': %s '%s' is not found",
+					file, patch.GetKind(), patch.GetName())
+			}
+			if err = patchObject(target, patch); err != nil {
+				return nil, fmt.Errorf("patch '%s': %s", file, err)
+			}
+		}
+	}
+
+	for _, obj := range objs {
+		obj.SetName(fmt.Sprintf("%s%s%s", k.NamePrefix, obj.GetName(), k.NameSuffix))
+		if err = addCommonLabels(obj, k.CommonLabels); err != nil {
+			return nil, err
+		}
+		if len(k.CommonAnnotations) > 0 {
+			obj.SetAnnotations(mergeStringMaps(obj.GetAnnotations(), k.CommonAnnotations))
+		}
+	}
+	return objs, nil
+}
+
+// readManifest read file and parse it as Kubernetes manifest.
+func readManifest(file string) ([]*unstructured.Unstructured, error) {
+	payload, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	objs, err := parseManifest(payload)
+	if err != nil {
+		return nil, fmt.Errorf("file '%s': %s", file, err)
+	}
+	return objs, nil
+}
+
+// findObject look for object with same group, kind and name in slice.
+func findObject(
+	objs []*unstructured.Unstructured, obj *unstructured.Unstructured) *unstructured.Unstructured {
+	gk := obj.GroupVersionKind().GroupKind()
+	for _, item := range objs {
+		if item.GroupVersionKind().GroupKind() == gk && item.GetName() == obj.GetName() {
+			return item
+		}
+	}
+	return nil
+}
+
+// patchObject apply patch on target object. Kinds known by client-go are patched using strategic
+// merge, while other kinds, as in custom resources, employ JSON merge patch.
+func patchObject(target, patch *unstructured.Unstructured) error {
+	var dataStruct runtime.Object
+	var patched map[string]interface{}
+	var targetJSON, patchJSON, patchedJSON []byte
+	var err error
+
+	if dataStruct, err = scheme.Scheme.New(target.GroupVersionKind()); err == nil {
+		if patched, err = strategicpatch.StrategicMergeMapPatch(
+			target.Object, patch.Object, dataStruct); err != nil {
+			return err
+		}
+		target.Object = patched
+		return nil
+	}
+
+	if targetJSON, err = json.Marshal(target.Object); err != nil {
+		return err
+	}
+	if patchJSON, err = json.Marshal(patch.Object); err != nil {
+		return err
+	}
+	if patchedJSON, err = jsonpatch.MergePatch(targetJSON, patchJSON); err != nil {
+		return err
+	}
+	return target.UnmarshalJSON(patchedJSON)
+}
+
+// addCommonLabels on object metadata, and on selectors and pod templates of services and workloads.
+func addCommonLabels(obj *unstructured.Unstructured, commonLabels map[string]string) error {
+	var fieldsList [][]string
+
+	if len(commonLabels) == 0 {
+		return nil
+	}
+	obj.SetLabels(mergeStringMaps(obj.GetLabels(), commonLabels))
+
+	switch {
+	case obj.GetKind() == "Service":
+		fieldsList = [][]string{{"spec", "selector"}}
+	case stringSliceContains(selectorKinds, obj.GetKind()):
+		fieldsList = [][]string{
+			{"spec", "selector", "matchLabels"},
+			{"spec", "template", "metadata", "labels"},
+		}
+	}
+
+	for _, fields := range fieldsList {
+		existing, _, err := unstructured.NestedStringMap(obj.Object, fields...)
+		if err != nil {
+			return fmt.Errorf("%s '%s': %s", obj.GetKind(), obj.GetName(), err)
+		}
+		if err = unstructured.SetNestedStringMap(
+			obj.Object, mergeStringMaps(existing, commonLabels), fields...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package galaxy
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestKustomizeBuildKustomization(t *testing.T) {
+	objs, err := BuildKustomization("../../test/namespaces/ns3/web")
+	assert.Nil(t, err)
+	assert.Len(t, objs, 2)
+
+	deployment := objs[0]
+	assert.Equal(t, "ns3-web", deployment.GetName())
+	assert.Equal(t, "web", deployment.GetLabels()["app"])
+	assert.Equal(t, "galaxy", deployment.GetAnnotations()["team"])
+
+	replicas, _, _ := unstructured.NestedInt64(deployment.Object, "spec", "replicas")
+	assert.Equal(t, int64(2), replicas)
+	matchLabels, _, _ := unstructured.NestedStringMap(
+		deployment.Object, "spec", "selector", "matchLabels")
+	assert.Equal(t, map[string]string{"app": "web"}, matchLabels)
+
+	// strategic merge keeps container ports, while image is patched
+	containers, _, _ := unstructured.NestedSlice(
+		deployment.Object, "spec", "template", "spec", "containers")
+	assert.Len(t, containers, 1)
+	container := containers[0].(map[string]interface{})
+	assert.Equal(t, "nginx:1.16", container["image"])
+	assert.NotNil(t, container["ports"])
+
+	service := objs[1]
+	assert.Equal(t, "ns3-web", service.GetName())
+	selector, _, _ := unstructured.NestedStringMap(service.Object, "spec", "selector")
+	assert.Equal(t, map[string]string{"app": "web"}, selector)
+}
+
+func TestKustomizeBuildKustomizationErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "galaxy-kustomize")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	write := func(name, payload string) {
+		assert.Nil(t, ioutil.WriteFile(path.Join(dir, name), []byte(payload), 0600))
+	}
+
+	// unsupported fields are not ignored
+	write(KustomizationFile, "configMapGenerator: []\n")
+	_, err = BuildKustomization(dir)
+	assert.NotNil(t, err)
+
+	// base pointing to itself
+	write(KustomizationFile, "bases:\n  - .\n")
+	_, err = BuildKustomization(dir)
+	assert.NotNil(t, err)
+
+	// patch without target
+	write("cm.yaml", "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm\n")
+	write("patch.yaml", "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: other\n")
+	write(KustomizationFile, "resources:\n  - cm.yaml\npatchesStrategicMerge:\n  - patch.yaml\n")
+	_, err = BuildKustomization(dir)
+	assert.NotNil(t, err)
+
+	// custom resources are patched using JSON merge patch
+	write("crd.yaml", "apiVersion: example.com/v1\nkind: Widget\nmetadata:\n  name: w\n"+
+		"spec:\n  size: 1\n  color: red\n")
+	write("patch.yaml", "apiVersion: example.com/v1\nkind: Widget\nmetadata:\n  name: w\n"+
+		"spec:\n  size: 2\n")
+	write(KustomizationFile, "resources:\n  - crd.yaml\npatchesStrategicMerge:\n  - patch.yaml\n")
+	objs, err := BuildKustomization(dir)
+	assert.Nil(t, err)
+	spec, _, _ := unstructured.NestedMap(objs[0].Object, "spec")
+	assert.Equal(t, map[string]interface{}{"size": int64(2), "color": "red"}, spec)
+}
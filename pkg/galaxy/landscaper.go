@@ -13,10 +13,13 @@ type Landscaper struct {
 	logger     *log.Entry         // logger
 	cfg        *LandscaperConfig  // landscaper runtime configuration
 	kubeCfg    *KubernetesConfig  // kubernetes related configuration
+	clients    *Clients           // kubernetes and helm clients cache
 	env        *Environment       // environment instance
 	ctxs       []*Context         // slice of context instances
 	kubeClient *KubeClient        // kubernetes api client
 	helmClient *HelmClient        // helm api client
+	source     SecretSource       // secret source for component secrets, optional
+	charts     *LocalCharts       // chart loader, including local chart directories
 	fileState  ldsc.StateProvider // landscaper release file state provider
 	helmState  ldsc.StateProvider // landscaper helm state provider
 	executor   ldsc.Executor      // landscaper executor
@@ -51,10 +54,11 @@ func (l *Landscaper) Bootstrap(ns, originalNs string, dryRun bool) error {
 
 	l.logger.Infof("Bootstraping Landscaper for namespace '%s' (originally '%s')", ns, originalNs)
 
-	if err = l.loadKubeClient(); err != nil {
+	if l.kubeClient, err = l.clients.Kube(l.kubeCfg.KubeContext); err != nil {
 		return err
 	}
-	if err = l.loadHelmClient(); err != nil {
+	tlsCfg := l.env.Tiller.Merge(l.cfg.TillerTLSConfig).WithDefaults(l.cfg.HelmHome)
+	if l.helmClient, err = l.clients.Helm(l.kubeCfg.KubeContext, tlsCfg); err != nil {
 		return err
 	}
 
@@ -64,6 +68,9 @@ func (l *Landscaper) Bootstrap(ns, originalNs string, dryRun bool) error {
 
 	kubeSecrets := ldsc.NewKubeSecretsReadWriteDeleter(l.kubeClient.Client.Core())
 	secretsReader := ldsc.NewEnvironmentSecretsReader()
+	if l.source != nil {
+		secretsReader = NewLandscaperSecretsReader(l.source, l.env, l.ctxs, originalNs)
+	}
 
 	l.fileState = ldsc.NewFileStateProvider(
 		e.ComponentFiles,
@@ -91,6 +98,7 @@ func (l *Landscaper) Bootstrap(ns, originalNs string, dryRun bool) error {
 // setup Landscaper environment and release prefix.
 func (l *Landscaper) setup(ns, originalNs string, dryRun bool) (*ldsc.Environment, error) {
 	var releasePrefix string
+	var files []string
 	var err error
 
 	if l.env.Transform.ReleasePrefix != "" {
@@ -100,17 +108,20 @@ func (l *Landscaper) setup(ns, originalNs string, dryRun bool) (*ldsc.Environmen
 			return nil, err
 		}
 	}
+	if files, err = l.pickReleaseFiles(ns); err != nil {
+		return nil, err
+	}
 
 	return &ldsc.Environment{
 		DryRun:                    dryRun,
 		Context:                   l.kubeCfg.KubeContext,
 		Namespace:                 ns,
 		Environment:               l.env.Name,
-		ComponentFiles:            l.pickReleaseFiles(ns),
+		ComponentFiles:            files,
 		ReleaseNamePrefix:         releasePrefix,
 		HelmHome:                  l.cfg.HelmHome,
 		TillerNamespace:           l.cfg.TillerNamespace,
-		ChartLoader:               ldsc.NewLocalCharts(l.cfg.HelmHome),
+		ChartLoader:               l.charts,
 		ConfigurationOverrideFile: l.cfg.OverrideFile,
 		Wait:                      l.cfg.WaitForResources,
 		WaitTimeout:               time.Duration(time.Duration(l.cfg.WaitTimeout) * time.Second),
@@ -118,8 +129,9 @@ func (l *Landscaper) setup(ns, originalNs string, dryRun bool) (*ldsc.Environmen
 	}, nil
 }
 
-// pickReleaseFiles select release components for the target namespace.
-func (l *Landscaper) pickReleaseFiles(ns string) []string {
+// pickReleaseFiles select release components for the target namespace. Releases employing local
+// chart directories are rewritten to refer to the pseudo repository.
+func (l *Landscaper) pickReleaseFiles(ns string) ([]string, error) {
 	var files []string
 	var releases []Release
 	var found bool
@@ -131,30 +143,29 @@ func (l *Landscaper) pickReleaseFiles(ns string) []string {
 		}
 		for _, release := range releases {
 			l.logger.Infof("Inspecting release '%s'", release.Component.Name)
-			files = append(files, release.File)
+			file, err := l.charts.ReleaseFile(release)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, file)
 		}
 	}
 
-	return files
-}
-
-// loadHelmClient creates a new instance of Helm API client.
-func (l *Landscaper) loadHelmClient() error {
-	l.helmClient = NewHelmClient(
-		l.cfg.HelmHome, l.cfg.TillerNamespace, l.cfg.TillerPort, l.cfg.TillerTimeout, l.kubeClient,
-	)
-	return l.helmClient.Load()
-}
-
-// loadKubeClient creates a new Kubernetes API client instance.
-func (l *Landscaper) loadKubeClient() error {
-	l.kubeClient = NewKubeClient(l.kubeCfg)
-	return l.kubeClient.Load()
+	return files, nil
 }
 
-// NewLandscaper instance a new Landscaper object.
+// NewLandscaper instance a new Landscaper object. Secret source is optional, when nil component
+// secrets are read from environment variables. Charts are loaded by local charts loader.
 func NewLandscaper(
-	cfg *LandscaperConfig, kubeCfg *KubernetesConfig, env *Environment, ctxs []*Context, raw bool) *Landscaper {
+	cfg *LandscaperConfig,
+	kubeCfg *KubernetesConfig,
+	clients *Clients,
+	env *Environment,
+	ctxs []*Context,
+	source SecretSource,
+	charts *LocalCharts,
+	raw bool,
+) *Landscaper {
 
 	log.SetFormatter(&log.TextFormatter{
 		DisableColors: raw,
@@ -166,7 +177,10 @@ func NewLandscaper(
 		logger:  log.WithField("type", "landscaper"),
 		cfg:     cfg,
 		kubeCfg: kubeCfg,
+		clients: clients,
 		env:     env,
 		ctxs:    ctxs,
+		source:  source,
+		charts:  charts,
 	}
 }
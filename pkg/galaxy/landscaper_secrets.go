@@ -0,0 +1,123 @@
+package galaxy
+
+import (
+	"fmt"
+	"strings"
+
+	ldsc "github.com/Eneco/landscaper/pkg/landscaper"
+	log "github.com/sirupsen/logrus"
+)
+
+// SecretsPathAnnotation component annotation with the secret source path where Landscaper secrets
+// are read from.
+const SecretsPathAnnotation = "galaxy/secrets-path"
+
+// LandscaperSecretsReader resolves Landscaper component secrets from a secret source, instead of
+// environment variables. Secret references are resolved, in order of precedence, as "path#key",
+// using component's path annotation, or using environment's Landscaper secrets path. References
+// not resolved to a path are read from environment variables, as upstream Landscaper does.
+type LandscaperSecretsReader struct {
+	logger     *log.Entry         // logger
+	source     SecretSource       // secret source
+	env        *Environment       // environment instance
+	ctxs       []*Context         // slice of context instances
+	originalNs string             // original namespace name
+	fallback   ldsc.SecretsReader // environment variables secrets reader
+}
+
+// Read secret values for component, where secretNames carry secret name and reference.
+func (l *LandscaperSecretsReader) Read(
+	componentName, namespace string, secretNames ldsc.SecretNames) (ldsc.SecretValues, error) {
+	var fallbackValues ldsc.SecretValues
+	var err error
+
+	logger := l.logger.WithFields(log.Fields{"component": componentName, "namespace": namespace})
+	logger.Debug("Reading secrets for component...")
+
+	values := ldsc.SecretValues{}
+	fallbackNames := ldsc.SecretNames{}
+	cache := make(map[string]map[string][]byte)
+
+	for name, ref := range secretNames {
+		var path, key string
+
+		if path, key, err = l.resolve(componentName, namespace, ref); err != nil {
+			return nil, err
+		}
+		if path == "" {
+			logger.Debugf("Secret '%s' is read from environment variables", name)
+			fallbackNames[name] = ref
+			continue
+		}
+
+		if _, found := cache[path]; !found {
+			logger.Debugf("Reading secret source path '%s'", path)
+			if cache[path], err = l.source.Read(path); err != nil {
+				return nil, err
+			}
+		}
+		value, found := cache[path][key]
+		if !found {
+			return nil, fmt.Errorf("key '%s' is not found on path '%s', for secret '%s' on component '%s'",
+				key, path, name, componentName)
+		}
+		values[name] = value
+	}
+
+	if len(fallbackNames) > 0 {
+		if fallbackValues, err = l.fallback.Read(componentName, namespace, fallbackNames); err != nil {
+			return nil, err
+		}
+		for name, value := range fallbackValues {
+			values[name] = value
+		}
+	}
+	return values, nil
+}
+
+// resolve secret reference into secret source path and key, path is empty when not resolved.
+func (l *LandscaperSecretsReader) resolve(componentName, namespace, ref string) (string, string, error) {
+	if idx := strings.LastIndex(ref, "#"); idx > 0 {
+		return ref[:idx], ref[idx+1:], nil
+	}
+	if component := l.component(componentName, namespace); component != nil {
+		if path, found := component.Annotations[SecretsPathAnnotation]; found && path != "" {
+			return path, ref, nil
+		}
+	}
+	if l.env.Secrets.LandscaperPath == "" {
+		return "", ref, nil
+	}
+
+	path, err := l.env.Interpolate(l.env.Secrets.LandscaperPath, []string{
+		fmt.Sprintf("NAMESPACE=%s", l.originalNs),
+		fmt.Sprintf("RELEASE=%s", componentName),
+	})
+	return path, ref, err
+}
+
+// component finds the component in contexts, by release name.
+func (l *LandscaperSecretsReader) component(componentName, namespace string) *Component {
+	for _, ctx := range l.ctxs {
+		for _, release := range ctx.Releases[namespace] {
+			if strings.EqualFold(release.Component.Name, componentName) {
+				return release.Component
+			}
+		}
+	}
+	return nil
+}
+
+// NewLandscaperSecretsReader instantiate a secrets reader for Landscaper components.
+func NewLandscaperSecretsReader(
+	source SecretSource, env *Environment, ctxs []*Context, originalNs string,
+) *LandscaperSecretsReader {
+	return &LandscaperSecretsReader{
+		logger:     log.WithFields(log.Fields{"type": "landscaperSecretsReader", "env": env.Name}),
+		source:     source,
+		env:        env,
+		ctxs:       ctxs,
+		originalNs: originalNs,
+		fallback:   ldsc.NewEnvironmentSecretsReader(),
+	}
+}
@@ -0,0 +1,67 @@
+package galaxy
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ldsc "github.com/Eneco/landscaper/pkg/landscaper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLandscaperSecretsReaderRead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "galaxy-landscaper-secrets")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	for path, value := range map[string]string{
+		"landscaper/ns1/d-ns1-app4/password": "env-path",
+		"app4/username":                      "annotation-path",
+		"explicit/token":                     "explicit-path",
+	} {
+		assert.Nil(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(path)), 0700))
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, path), []byte(value), 0600))
+	}
+
+	env := &Environment{Name: "dev", Secrets: SecretsSpec{
+		LandscaperPath: "landscaper/${NAMESPACE}/${RELEASE}",
+	}}
+	ctx := NewContext()
+	ctx.Releases["ns1-d"] = []Release{{
+		Namespace: "ns1-d",
+		Component: &Component{
+			Name:        "d-ns1-app4",
+			Annotations: map[string]string{SecretsPathAnnotation: "app4"},
+		},
+	}}
+	source := NewFileSecretSource(dir, "", "")
+
+	r := NewLandscaperSecretsReader(source, env, []*Context{ctx}, "ns1")
+	values, err := r.Read("d-ns1-app4", "ns1-d", ldsc.SecretNames{
+		"username": "username",
+		"token":    "explicit#token",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, ldsc.SecretValues{
+		"username": []byte("annotation-path"),
+		"token":    []byte("explicit-path"),
+	}, values)
+
+	// component without annotation, relying on environment path
+	ctx.Releases["ns1-d"][0].Component.Annotations = nil
+	values, err = r.Read("d-ns1-app4", "ns1-d", ldsc.SecretNames{"password": "password"})
+	assert.Nil(t, err)
+	assert.Equal(t, ldsc.SecretValues{"password": []byte("env-path")}, values)
+
+	_, err = r.Read("d-ns1-app4", "ns1-d", ldsc.SecretNames{"missing": "missing"})
+	assert.NotNil(t, err)
+
+	// without paths, secrets are read from environment variables
+	env.Secrets.LandscaperPath = ""
+	os.Setenv("GALAXY_TEST_SECRET", "environment")
+	defer os.Unsetenv("GALAXY_TEST_SECRET")
+	values, err = r.Read("d-ns1-app4", "ns1-d", ldsc.SecretNames{"secret": "galaxy-test-secret"})
+	assert.Nil(t, err)
+	assert.Equal(t, ldsc.SecretValues{"secret": []byte("environment")}, values)
+}
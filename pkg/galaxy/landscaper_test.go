@@ -17,7 +17,10 @@ func TestLandscaperNewLandscaper(t *testing.T) {
 	env, _ := dotGalaxy.GetEnvironment("dev")
 
 	cfg := NewConfig()
-	landscaper = NewLandscaper(cfg.LandscaperConfig, cfg.KubernetesConfig, env, g.Modified["dev"], cfg.Raw)
+	clients := NewClients(cfg.KubernetesConfig, cfg.LandscaperConfig, cfg.VaultHandlerConfig)
+	charts := NewLocalCharts(dotGalaxy.Spec.Namespaces.BaseDir, cfg.HelmHome)
+	landscaper = NewLandscaper(cfg.LandscaperConfig, cfg.KubernetesConfig, clients, env,
+		g.Modified["dev"], nil, charts, cfg.Raw)
 }
 
 func TestLandscaperBootstrap(t *testing.T) {
@@ -0,0 +1,190 @@
+package galaxy
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	ldsc "github.com/Eneco/landscaper/pkg/landscaper"
+	log "github.com/sirupsen/logrus"
+	yaml "gopkg.in/yaml.v2"
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/helm/helmpath"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+)
+
+// LocalChartsRepo pseudo repository name given to local chart directories, since Landscaper expects
+// charts as "repo/name".
+const LocalChartsRepo = "galaxy-local"
+
+// LocalCharts chart loader wrapping Landscaper's, charts referenced as a path are loaded from
+// directories relative to base directory and packaged on the fly, other references are delegated
+// to Landscaper's loader. Release files are rewritten when the chart reference is changed.
+type LocalCharts struct {
+	logger  *log.Entry        // logger
+	baseDir string            // base directory for chart paths
+	home    helmpath.Home     // helm home, for repositories index
+	loader  ldsc.ChartLoader  // landscaper chart loader
+	dirs    map[string]string // chart directory per pseudo repository chart name
+	workDir string            // temporary directory for packages and release files
+}
+
+// Load chart by reference, directories registered before are packaged, while others are loaded by
+// Landscaper.
+func (l *LocalCharts) Load(chartRef string) (*chart.Chart, string, error) {
+	var ch *chart.Chart
+	var chartPath string
+	var err error
+
+	name, version := splitChartRef(chartRef)
+	if !strings.HasPrefix(name, LocalChartsRepo+"/") {
+		return l.loader.Load(chartRef)
+	}
+
+	dir, found := l.dirs[strings.TrimPrefix(name, LocalChartsRepo+"/")]
+	if !found {
+		return nil, "", fmt.Errorf("local chart '%s' is not registered", chartRef)
+	}
+	logger := l.logger.WithFields(log.Fields{"chartRef": chartRef, "dir": dir})
+
+	if ch, err = chartutil.LoadDir(dir); err != nil {
+		return nil, "", err
+	}
+	if version != "" && version != ch.Metadata.Version {
+		return nil, "", fmt.Errorf("local chart '%s' has version '%s', expected '%s'",
+			dir, ch.Metadata.Version, version)
+	}
+	if err = l.ensureWorkDir(); err != nil {
+		return nil, "", err
+	}
+	logger.Debug("Packaging local chart...")
+	if chartPath, err = chartutil.Save(ch, l.workDir); err != nil {
+		return nil, "", err
+	}
+	return ch, chartPath, nil
+}
+
+// Register local chart directory, returns chart reference under pseudo repository.
+func (l *LocalCharts) Register(chartRef string) (string, error) {
+	name, version := splitChartRef(chartRef)
+	dir := l.ChartDir(name)
+	chartName := filepath.Base(dir)
+
+	if !isDir(dir) {
+		return "", fmt.Errorf("local chart directory is not found at '%s'", dir)
+	}
+	if registered, found := l.dirs[chartName]; found && registered != dir {
+		return "", fmt.Errorf("local charts '%s' and '%s' share the same name", registered, dir)
+	}
+	l.dirs[chartName] = dir
+
+	ref := fmt.Sprintf("%s/%s", LocalChartsRepo, chartName)
+	if version != "" {
+		ref = fmt.Sprintf("%s:%s", ref, version)
+	}
+	return ref, nil
+}
+
+// ChartDir path to local chart directory, relative to base directory.
+func (l *LocalCharts) ChartDir(name string) string {
+	if filepath.IsAbs(name) {
+		return filepath.Clean(name)
+	}
+	return filepath.Clean(filepath.Join(l.baseDir, name))
+}
+
+// ReleaseFile writes a copy of release file, with local chart reference rewritten to the pseudo
+// repository, or with chart version pinned by environment resolved, returns the new file path.
+// Files where the chart reference is kept are not copied.
+func (l *LocalCharts) ReleaseFile(release Release) (string, error) {
+	var doc yaml.MapSlice
+	var ref string
+	var payload []byte
+	var err error
+
+	if isLocalChartRef(release.Component.Release.Chart) {
+		ref, err = l.Register(release.Component.Release.Chart)
+	} else {
+		ref, err = resolveChartRef(l.home, release.Component.Release.Chart)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err = yaml.Unmarshal(readFile(release.File), &doc); err != nil {
+		return "", err
+	}
+	changed := false
+	for i, item := range doc {
+		if item.Key != "release" {
+			continue
+		}
+		releaseDoc, ok := item.Value.(yaml.MapSlice)
+		if !ok {
+			return "", fmt.Errorf("file '%s': unexpected release format", release.File)
+		}
+		for j, releaseItem := range releaseDoc {
+			if releaseItem.Key == "chart" && releaseItem.Value != ref {
+				releaseDoc[j].Value = ref
+				changed = true
+			}
+		}
+		doc[i].Value = releaseDoc
+	}
+	if !changed {
+		return release.File, nil
+	}
+
+	if payload, err = yaml.Marshal(doc); err != nil {
+		return "", err
+	}
+	if err = l.ensureWorkDir(); err != nil {
+		return "", err
+	}
+	file := filepath.Join(l.workDir, strings.Replace(filepath.Clean(release.File), "/", "_", -1))
+	l.logger.WithFields(log.Fields{"file": release.File, "chartRef": ref}).
+		Debugf("Release file with chart reference rewritten at '%s'", file)
+	return file, ioutil.WriteFile(file, payload, 0600)
+}
+
+// ensureWorkDir creates temporary work directory, when not created yet.
+func (l *LocalCharts) ensureWorkDir() error {
+	var err error
+
+	if l.workDir != "" {
+		return nil
+	}
+	l.workDir, err = ioutil.TempDir("", "galaxy-local-charts")
+	return err
+}
+
+// Close removes temporary work directory.
+func (l *LocalCharts) Close() {
+	if l.workDir == "" {
+		return
+	}
+	if err := os.RemoveAll(l.workDir); err != nil {
+		l.logger.Warnf("Unable to remove work directory '%s': %s", l.workDir, err)
+	}
+	l.workDir = ""
+}
+
+// isLocalChartRef checks if chart reference is a path, as in "./charts/app".
+func isLocalChartRef(chartRef string) bool {
+	return strings.HasPrefix(chartRef, "./") ||
+		strings.HasPrefix(chartRef, "../") ||
+		strings.HasPrefix(chartRef, "/")
+}
+
+// NewLocalCharts instantiate local charts loader, for base directory and Helm home.
+func NewLocalCharts(baseDir, helmHome string) *LocalCharts {
+	return &LocalCharts{
+		logger:  log.WithFields(log.Fields{"type": "localCharts", "baseDir": baseDir}),
+		baseDir: baseDir,
+		home:    helmpath.Home(helmHome),
+		loader:  ldsc.NewLocalCharts(helmHome),
+		dirs:    make(map[string]string),
+	}
+}
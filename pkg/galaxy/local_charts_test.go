@@ -0,0 +1,98 @@
+package galaxy
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+)
+
+// fakeLocalChart creates chart directory "charts/<name>" under base directory.
+func fakeLocalChart(t *testing.T, baseDir, name, version string) {
+	dir := filepath.Join(baseDir, "charts")
+	assert.Nil(t, os.MkdirAll(dir, 0755))
+
+	_, err := chartutil.Create(&chart.Metadata{Name: name, Version: version}, dir)
+	assert.Nil(t, err)
+}
+
+func TestLocalChartsLoad(t *testing.T) {
+	baseDir, err := ioutil.TempDir("", "galaxy-local-charts-base")
+	assert.Nil(t, err)
+	defer os.RemoveAll(baseDir)
+	fakeLocalChart(t, baseDir, "myapp", "0.1.0")
+
+	l := NewLocalCharts(baseDir, "")
+	defer l.Close()
+
+	_, err = l.Register("./charts/missing")
+	assert.NotNil(t, err)
+
+	ref, err := l.Register("./charts/myapp:0.1.0")
+	assert.Nil(t, err)
+	assert.Equal(t, LocalChartsRepo+"/myapp:0.1.0", ref)
+
+	ch, chartPath, err := l.Load(ref)
+	assert.Nil(t, err)
+	assert.Equal(t, "myapp", ch.Metadata.Name)
+	assert.True(t, fileExists(chartPath))
+
+	_, _, err = l.Load(LocalChartsRepo + "/myapp:9.9.9")
+	assert.NotNil(t, err)
+	_, _, err = l.Load(LocalChartsRepo + "/other")
+	assert.NotNil(t, err)
+
+	// distinct directories sharing the same name
+	assert.Nil(t, os.MkdirAll(filepath.Join(baseDir, "other"), 0755))
+	_, err = chartutil.Create(&chart.Metadata{Name: "myapp", Version: "0.2.0"},
+		filepath.Join(baseDir, "other"))
+	assert.Nil(t, err)
+	_, err = l.Register("./other/myapp")
+	assert.NotNil(t, err)
+}
+
+func TestLocalChartsReleaseFile(t *testing.T) {
+	baseDir, err := ioutil.TempDir("", "galaxy-local-charts-base")
+	assert.Nil(t, err)
+	defer os.RemoveAll(baseDir)
+	fakeLocalChart(t, baseDir, "myapp", "0.1.0")
+
+	file := filepath.Join(baseDir, "myapp.yaml")
+	assert.Nil(t, ioutil.WriteFile(file, []byte(`---
+name: myapp
+release:
+  chart: ./charts/myapp
+  version: 0.1.0
+configuration:
+  replicas: 2
+`), 0600))
+
+	ctx := NewContext()
+	assert.Nil(t, ctx.AddFile("ns1", file))
+
+	l := NewLocalCharts(baseDir, "")
+	rewritten, err := l.ReleaseFile(ctx.Releases["ns1"][0])
+	assert.Nil(t, err)
+	assert.NotEqual(t, file, rewritten)
+
+	rewrittenCtx := NewContext()
+	assert.Nil(t, rewrittenCtx.AddFile("ns1", rewritten))
+	release := rewrittenCtx.Releases["ns1"][0]
+	assert.Equal(t, LocalChartsRepo+"/myapp", release.Component.Release.Chart)
+	assert.EqualValues(t, 2, release.Component.Configuration["replicas"])
+
+	// work directory is removed on close
+	l.Close()
+	assert.False(t, fileExists(rewritten))
+
+	// release files employing repository charts are kept
+	ctx = NewContext()
+	assert.Nil(t, ctx.AddFile("ns1", "../../test/namespaces/ns1/app1.yaml"))
+	kept, err := l.ReleaseFile(ctx.Releases["ns1"][0])
+	assert.Nil(t, err)
+	assert.Equal(t, "../../test/namespaces/ns1/app1.yaml", kept)
+}
@@ -0,0 +1,317 @@
+package galaxy
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/jsonmergepatch"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// KindLabel label carrying the kind of file an object comes from, employed on manifests.
+	KindLabel = "galaxy/kind"
+	// KindManifest value of kind label for objects created from Kubernetes manifests.
+	KindManifest = "manifest"
+	// LastAppliedAnnotation annotation carrying the object as last applied, employed to compute the
+	// patch on update, without dropping fields assigned by the cluster.
+	LastAppliedAnnotation = "galaxy/last-applied"
+)
+
+// defaultPruneKinds kinds inspected when pruning manifest objects, besides the kinds planned in
+// environment, as kubectl does.
+var defaultPruneKinds = []schema.GroupKind{
+	{Group: "", Kind: "ConfigMap"},
+	{Group: "", Kind: "PersistentVolumeClaim"},
+	{Group: "", Kind: "Secret"},
+	{Group: "", Kind: "Service"},
+	{Group: "", Kind: "ServiceAccount"},
+	{Group: "apps", Kind: "DaemonSet"},
+	{Group: "apps", Kind: "Deployment"},
+	{Group: "apps", Kind: "StatefulSet"},
+	{Group: "batch", Kind: "CronJob"},
+	{Group: "batch", Kind: "Job"},
+	{Group: "extensions", Kind: "Ingress"},
+}
+
+// ManifestHandler applies plain Kubernetes manifests and kustomize overlays to the target
+// namespace, using create or patch semantics, and prune the objects no longer planned. Objects are
+// labeled with ownership labels. Cluster scoped kinds are not supported.
+type ManifestHandler struct {
+	logger  *log.Entry        // logger
+	client  dynamic.Interface // kubernetes dynamic client
+	mapper  meta.RESTMapper   // maps kinds to api resources
+	env     string            // environment name
+	baseDir string            // namespaces base directory
+	ctxs    []*Context        // slice of context instances
+	dryRun  bool              // dry-run flag
+}
+
+// sourcedObject kubernetes object, and the manifest file or overlay directory declaring it.
+type sourcedObject struct {
+	source string                     // manifest file or overlay directory
+	obj    *unstructured.Unstructured // kubernetes object
+}
+
+// Apply planned manifests and overlays on namespace, reporting if each object is created, updated
+// or unchanged.
+func (m *ManifestHandler) Apply(ns string) error {
+	for _, ctx := range m.ctxs {
+		for _, o := range namespaceObjects(ctx, ns) {
+			if err := m.apply(ns, o.source, o.obj); err != nil {
+				return fmt.Errorf("source '%s', %s '%s': %s",
+					o.source, o.obj.GetKind(), o.obj.GetName(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// resource client for informed kind, namespaced when resource is namespace scoped.
+func (m *ManifestHandler) resource(gvk schema.GroupVersionKind, ns string) (
+	dynamic.ResourceInterface, bool, error) {
+	var mapping *meta.RESTMapping
+	var err error
+
+	if gvk.Version == "" {
+		mapping, err = m.mapper.RESTMapping(gvk.GroupKind())
+	} else {
+		mapping, err = m.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		return m.client.Resource(mapping.Resource).Namespace(ns), true, nil
+	}
+	return m.client.Resource(mapping.Resource), false, nil
+}
+
+// apply a single object, from manifest file. Existing objects are patched with a three-way JSON
+// merge patch, between last applied, planned and current object, as kubectl does.
+func (m *ManifestHandler) apply(ns, file string, original *unstructured.Unstructured) error {
+	var client dynamic.ResourceInterface
+	var namespaced bool
+	var existing *unstructured.Unstructured
+	var payload, applied, modified, current, patch []byte
+	var err error
+
+	if payload, err = original.MarshalJSON(); err != nil {
+		return err
+	}
+	hash := fmt.Sprintf("%x", sha256.Sum256(payload))
+
+	obj := original.DeepCopy()
+	if client, namespaced, err = m.resource(obj.GroupVersionKind(), ns); err != nil {
+		return err
+	}
+	if !namespaced {
+		return fmt.Errorf("cluster scoped kind '%s' is not supported", obj.GetKind())
+	}
+	obj.SetNamespace(ns)
+	obj.SetLabels(mergeStringMaps(obj.GetLabels(), map[string]string{
+		ManagedByLabel:   ManagedByValue,
+		EnvironmentLabel: m.env,
+		KindLabel:        KindManifest,
+	}))
+	obj.SetAnnotations(mergeStringMaps(obj.GetAnnotations(), map[string]string{
+		SourceFileAnnotation:   relativePath(m.baseDir, file),
+		ManifestHashAnnotation: hash,
+	}))
+	if applied, err = obj.MarshalJSON(); err != nil {
+		return err
+	}
+	obj.SetAnnotations(mergeStringMaps(obj.GetAnnotations(), map[string]string{
+		LastAppliedAnnotation: string(applied),
+	}))
+
+	logger := m.logger.WithFields(log.Fields{
+		"namespace": obj.GetNamespace(), "kind": obj.GetKind(), "name": obj.GetName(), "file": file,
+	})
+
+	if existing, err = client.Get(obj.GetName(), metav1.GetOptions{}); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		if m.dryRun {
+			logger.Info("DRY-RUN: Object would be created.")
+			return nil
+		}
+		logger.Info("Creating object...")
+		_, err = client.Create(obj)
+		return err
+	}
+
+	if existing.GetAnnotations()[ManifestHashAnnotation] == hash {
+		logger.Info("Object is unchanged.")
+		return nil
+	}
+	if m.dryRun {
+		logger.Infof("DRY-RUN: Object would be updated (hash '%s' to '%s')",
+			existing.GetAnnotations()[ManifestHashAnnotation], hash)
+		return nil
+	}
+	if modified, err = obj.MarshalJSON(); err != nil {
+		return err
+	}
+	if current, err = existing.MarshalJSON(); err != nil {
+		return err
+	}
+	if patch, err = jsonmergepatch.CreateThreeWayJSONMergePatch(
+		[]byte(existing.GetAnnotations()[LastAppliedAnnotation]), modified, current); err != nil {
+		return err
+	}
+	logger.Info("Updating object...")
+	_, err = client.Patch(obj.GetName(), types.MergePatchType, patch)
+	return err
+}
+
+// Prune objects on namespace created from manifests or overlays, owned by Galaxy in the same
+// environment, and not planned anymore. Kinds inspected are the ones planned in environment, plus
+// defaults.
+func (m *ManifestHandler) Prune(ns string) error {
+	planned := plannedManifestObjects(m.ctxs, ns)
+	selector := labels.SelectorFromSet(labels.Set{
+		ManagedByLabel:   ManagedByValue,
+		EnvironmentLabel: m.env,
+		KindLabel:        KindManifest,
+	})
+
+	logger := m.logger.WithFields(log.Fields{"namespace": ns, "selector": selector.String()})
+	logger.Info("Looking for manifest objects to prune...")
+
+	for _, gk := range m.pruneKinds() {
+		var client dynamic.ResourceInterface
+		var namespaced bool
+		var list *unstructured.UnstructuredList
+		var err error
+
+		if client, namespaced, err = m.resource(gk.WithVersion(""), ns); err != nil {
+			logger.Debugf("Skipping kind '%s': %s", gk.String(), err)
+			continue
+		}
+		if !namespaced {
+			continue
+		}
+		if list, err = client.List(metav1.ListOptions{LabelSelector: selector.String()}); err != nil {
+			return err
+		}
+
+		for _, item := range list.Items {
+			name := fmt.Sprintf("%s/%s", gk.Kind, item.GetName())
+			if _, found := planned[name]; found {
+				continue
+			}
+			if m.dryRun {
+				logger.Infof("DRY-RUN: Object '%s' would be pruned (file '%s')",
+					name, item.GetAnnotations()[SourceFileAnnotation])
+				continue
+			}
+			logger.Infof("Pruning object '%s' (file '%s')",
+				name, item.GetAnnotations()[SourceFileAnnotation])
+			if err = client.Delete(item.GetName(), &metav1.DeleteOptions{}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// pruneKinds default kinds, plus kinds planned on environment, without duplicates.
+func (m *ManifestHandler) pruneKinds() []schema.GroupKind {
+	kinds := append([]schema.GroupKind{}, defaultPruneKinds...)
+	for _, ctx := range m.ctxs {
+		var namespaces []string
+
+		for ns := range ctx.Manifests {
+			namespaces = append(namespaces, ns)
+		}
+		for ns := range ctx.Overlays {
+			namespaces = append(namespaces, ns)
+		}
+		for _, ns := range namespaces {
+			for _, o := range namespaceObjects(ctx, ns) {
+				gk := o.obj.GroupVersionKind().GroupKind()
+				if !groupKindSliceContains(kinds, gk) {
+					kinds = append(kinds, gk)
+				}
+			}
+		}
+	}
+	return kinds
+}
+
+// namespaceObjects objects planned on namespace, from manifests and kustomize overlays.
+func namespaceObjects(ctx *Context, ns string) []sourcedObject {
+	var objs []sourcedObject
+
+	for _, manifest := range ctx.Manifests[ns] {
+		for _, obj := range manifest.Objects {
+			objs = append(objs, sourcedObject{source: manifest.File, obj: obj})
+		}
+	}
+	for _, overlay := range ctx.Overlays[ns] {
+		for _, obj := range overlay.Objects {
+			objs = append(objs, sourcedObject{source: overlay.Dir, obj: obj})
+		}
+	}
+	return objs
+}
+
+// plannedManifestObjects map of objects, as "kind/name", and the manifest file or overlay directory
+// declaring them.
+func plannedManifestObjects(ctxs []*Context, ns string) map[string]string {
+	planned := make(map[string]string)
+	for _, ctx := range ctxs {
+		for _, o := range namespaceObjects(ctx, ns) {
+			planned[fmt.Sprintf("%s/%s", o.obj.GetKind(), o.obj.GetName())] = o.source
+		}
+	}
+	return planned
+}
+
+// groupKindSliceContains checks if group-kind is present in slice.
+func groupKindSliceContains(slice []schema.GroupKind, gk schema.GroupKind) bool {
+	for _, item := range slice {
+		if item == gk {
+			return true
+		}
+	}
+	return false
+}
+
+// manifestsExcluded label selector requirement to exclude objects created from manifests, employed
+// when pruning objects of other file kinds.
+func manifestsExcluded() labels.Requirement {
+	requirement, _ := labels.NewRequirement(KindLabel, selection.NotEquals, []string{KindManifest})
+	return *requirement
+}
+
+// NewManifestHandler instantiate Kubernetes manifests handler for environment, source file
+// annotations are relative to namespaces base directory.
+func NewManifestHandler(
+	client dynamic.Interface,
+	mapper meta.RESTMapper,
+	env, baseDir string,
+	ctxs []*Context,
+	dryRun bool,
+) *ManifestHandler {
+	return &ManifestHandler{
+		logger:  log.WithFields(log.Fields{"type": "manifestHandler", "env": env, "dryRun": dryRun}),
+		client:  client,
+		mapper:  mapper,
+		env:     env,
+		baseDir: baseDir,
+		ctxs:    ctxs,
+		dryRun:  dryRun,
+	}
+}
@@ -0,0 +1,109 @@
+package galaxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// fakeRESTMapper maps the core kinds employed on manifests fixtures.
+func fakeRESTMapper() meta.RESTMapper {
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{{Version: "v1"}})
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Service"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+	return mapper
+}
+
+func TestManifestHandler(t *testing.T) {
+	baseDir := "../../test/namespaces"
+	ctx := NewContext()
+	err := ctx.AddFile("ns2-t", "../../test/namespaces/ns2/config.yaml")
+	assert.Nil(t, err)
+
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	configMaps := client.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).
+		Namespace("ns2-t")
+
+	// dry-run does not change the cluster
+	m := NewManifestHandler(client, fakeRESTMapper(), "tst", baseDir, []*Context{ctx}, true)
+	assert.Nil(t, m.Apply("ns2-t"))
+	_, err = configMaps.Get("config", metav1.GetOptions{})
+	assert.NotNil(t, err)
+
+	m = NewManifestHandler(client, fakeRESTMapper(), "tst", baseDir, []*Context{ctx}, false)
+	assert.Nil(t, m.Apply("ns2-t"))
+	configMap, err := configMaps.Get("config", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "ns2-t", configMap.GetNamespace())
+	assert.Equal(t, ManagedByValue, configMap.GetLabels()[ManagedByLabel])
+	assert.Equal(t, "tst", configMap.GetLabels()[EnvironmentLabel])
+	assert.Equal(t, KindManifest, configMap.GetLabels()[KindLabel])
+	assert.Equal(t, "ns2/config.yaml", configMap.GetAnnotations()[SourceFileAnnotation])
+	assert.NotEmpty(t, configMap.GetAnnotations()[ManifestHashAnnotation])
+
+	// applying again leaves objects unchanged
+	assert.Nil(t, m.Apply("ns2-t"))
+	verbs := map[string]int{}
+	for _, action := range client.Actions() {
+		verbs[action.GetVerb()]++
+	}
+	assert.Equal(t, 2, verbs["create"])
+	assert.Equal(t, 0, verbs["patch"])
+
+	// changes are patched, keeping fields assigned by the cluster
+	services := client.Resource(schema.GroupVersionResource{Version: "v1", Resource: "services"}).
+		Namespace("ns2-t")
+	service, err := services.Get("app", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Nil(t, unstructured.SetNestedField(service.Object, "10.0.0.1", "spec", "clusterIP"))
+	_, err = services.Update(service)
+	assert.Nil(t, err)
+
+	client.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, nil
+	})
+	ctx.Manifests["ns2-t"][0].Objects[1].SetLabels(map[string]string{"tier": "web"})
+	assert.Nil(t, m.Apply("ns2-t"))
+	var patches []string
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "patch" {
+			patches = append(patches, string(action.(k8stesting.PatchAction).GetPatch()))
+		}
+	}
+	assert.Len(t, patches, 1)
+	assert.Contains(t, patches[0], `"tier":"web"`)
+	assert.NotContains(t, patches[0], "clusterIP")
+
+	// cluster scoped kinds are not supported
+	ctx.Manifests["ns2-t"][0].Objects[0].SetKind("Namespace")
+	assert.NotNil(t, m.Apply("ns2-t"))
+
+	// kinds unknown to the cluster are reported
+	ctx.Manifests["ns2-t"][0].Objects[0].SetKind("Unknown")
+	assert.NotNil(t, m.Apply("ns2-t"))
+}
+
+func TestManifestHandlerPruneKinds(t *testing.T) {
+	ctx := NewContext()
+	err := ctx.AddFile("ns2-t", "../../test/namespaces/ns2/config.yaml")
+	assert.Nil(t, err)
+
+	m := NewManifestHandler(nil, nil, "tst", "", []*Context{ctx}, false)
+	assert.Equal(t, defaultPruneKinds, m.pruneKinds())
+
+	ctx.Manifests["ns2-t"][0].Objects[0].SetAPIVersion("example.com/v1")
+	assert.Len(t, m.pruneKinds(), len(defaultPruneKinds)+1)
+
+	assert.Equal(t, map[string]string{
+		"ConfigMap/config": "../../test/namespaces/ns2/config.yaml",
+		"Service/app":      "../../test/namespaces/ns2/config.yaml",
+	}, plannedManifestObjects([]*Context{ctx}, "ns2-t"))
+}
@@ -0,0 +1,123 @@
+package galaxy
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	coreclient "k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset/typed/core/internalversion"
+)
+
+// NamespaceHandler creates or updates target namespaces, with labels and annotations declared on
+// dot-galaxy namespace entries.
+type NamespaceHandler struct {
+	logger     *log.Entry                  // logger
+	namespaces coreclient.NamespacesGetter // kubernetes namespaces client
+	env        *Environment                // environment instance
+	dryRun     bool                        // dry-run flag
+}
+
+// Ensure target namespace exists, when entry allows creating it, and carries declared labels and
+// annotations. Labels and annotations are interpolated with environment variables.
+func (n *NamespaceHandler) Ensure(ns, originalNs string, spec *NamespaceSpec) error {
+	var namespace *core.Namespace
+	var labels map[string]string
+	var annotations map[string]string
+	var err error
+
+	logger := n.logger.WithFields(log.Fields{"namespace": ns, "originalNs": originalNs})
+
+	if labels, err = n.interpolate(spec.Labels, originalNs); err != nil {
+		return err
+	}
+	if annotations, err = n.interpolate(spec.Annotations, originalNs); err != nil {
+		return err
+	}
+
+	if namespace, err = n.namespaces.Namespaces().Get(ns, metav1.GetOptions{}); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		if !spec.Create {
+			logger.Warn("Namespace is not found, and it's not configured to be created!")
+			return nil
+		}
+		if n.dryRun {
+			logger.Infof("DRY-RUN: Namespace would be created (labels '%v', annotations '%v')",
+				labels, annotations)
+			return nil
+		}
+
+		logger.Info("Creating namespace...")
+		_, err = n.namespaces.Namespaces().Create(&core.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: ns, Labels: labels, Annotations: annotations},
+		})
+		return err
+	}
+
+	labelsModified := n.merge(&namespace.ObjectMeta.Labels, labels)
+	annotationsModified := n.merge(&namespace.ObjectMeta.Annotations, annotations)
+	if !labelsModified && !annotationsModified {
+		logger.Debug("Namespace is up to date.")
+		return nil
+	}
+	if n.dryRun {
+		logger.Infof("DRY-RUN: Namespace would be updated (labels '%v', annotations '%v')",
+			labels, annotations)
+		return nil
+	}
+
+	logger.Info("Updating namespace labels and annotations...")
+	_, err = n.namespaces.Namespaces().Update(namespace)
+	return err
+}
+
+// merge declared entries on existing map, returns true when existing map is modified. Entries not
+// declared are kept.
+func (n *NamespaceHandler) merge(existing *map[string]string, declared map[string]string) bool {
+	modified := false
+	for k, v := range declared {
+		if current, found := (*existing)[k]; found && current == v {
+			continue
+		}
+		if *existing == nil {
+			*existing = make(map[string]string)
+		}
+		(*existing)[k] = v
+		modified = true
+	}
+	return modified
+}
+
+// interpolate map values using environment variables, and original namespace name.
+func (n *NamespaceHandler) interpolate(
+	entries map[string]string, originalNs string) (map[string]string, error) {
+	var err error
+
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	interpolated := make(map[string]string, len(entries))
+	for k, v := range entries {
+		if interpolated[k], err = n.env.Interpolate(v, []string{
+			fmt.Sprintf("NAMESPACE=%s", originalNs),
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return interpolated, nil
+}
+
+// NewNamespaceHandler instantiate namespace handler.
+func NewNamespaceHandler(
+	namespaces coreclient.NamespacesGetter, env *Environment, dryRun bool) *NamespaceHandler {
+	return &NamespaceHandler{
+		logger:     log.WithFields(log.Fields{"type": "namespaceHandler", "env": env.Name}),
+		namespaces: namespaces,
+		env:        env,
+		dryRun:     dryRun,
+	}
+}
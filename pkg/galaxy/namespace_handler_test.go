@@ -0,0 +1,55 @@
+package galaxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset/fake"
+)
+
+func TestNamespaceHandlerEnsure(t *testing.T) {
+	env := &Environment{Name: "dev", Transform: Transform{NamespaceSuffix: "-d"}}
+	spec := &NamespaceSpec{
+		Name:        "ns1",
+		Labels:      map[string]string{"team": "galaxy"},
+		Annotations: map[string]string{"origin": "${NAMESPACE}${NAMESPACE_SUFFIX}"},
+	}
+	kube := fake.NewSimpleClientset()
+
+	// dry-run, and namespace is not meant to be created
+	err := NewNamespaceHandler(kube.Core(), env, true).Ensure("ns1-d", "ns1", spec)
+	assert.Nil(t, err)
+	err = NewNamespaceHandler(kube.Core(), env, false).Ensure("ns1-d", "ns1", spec)
+	assert.Nil(t, err)
+	_, err = kube.Core().Namespaces().Get("ns1-d", metav1.GetOptions{})
+	assert.NotNil(t, err)
+
+	spec.Create = true
+	err = NewNamespaceHandler(kube.Core(), env, true).Ensure("ns1-d", "ns1", spec)
+	assert.Nil(t, err)
+	_, err = kube.Core().Namespaces().Get("ns1-d", metav1.GetOptions{})
+	assert.NotNil(t, err)
+
+	n := NewNamespaceHandler(kube.Core(), env, false)
+	err = n.Ensure("ns1-d", "ns1", spec)
+	assert.Nil(t, err)
+	namespace, err := kube.Core().Namespaces().Get("ns1-d", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "galaxy", namespace.Labels["team"])
+	assert.Equal(t, "ns1-d", namespace.Annotations["origin"])
+
+	// existing labels are kept, declared labels are updated
+	kube = fake.NewSimpleClientset(&core.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "ns1-d",
+		Labels: map[string]string{"team": "other", "extra": "true"},
+	}})
+	n = NewNamespaceHandler(kube.Core(), env, false)
+	err = n.Ensure("ns1-d", "ns1", spec)
+	assert.Nil(t, err)
+	namespace, err = kube.Core().Namespaces().Get("ns1-d", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"team": "galaxy", "extra": "true"}, namespace.Labels)
+	assert.Equal(t, "ns1-d", namespace.Annotations["origin"])
+}
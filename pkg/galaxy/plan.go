@@ -31,6 +31,9 @@ func (p *Plan) ContextForEnvironment() (*Context, error) {
 			return nil, err
 		}
 	}
+	if len(p.env.ChartVersions) > 0 {
+		p.pinChartVersions()
+	}
 	p.renameNamespaces()
 
 	return p.envCtx, nil
@@ -103,16 +106,30 @@ func (p *Plan) renameReleases() error {
 	})
 }
 
+// pinChartVersions override chart version of releases with the version, or semver constraint,
+// declared for the chart on environment. Local charts are not affected.
+func (p *Plan) pinChartVersions() {
+	p.logger.Info("Pinning chart versions...")
+	for ns, releases := range p.envCtx.Releases {
+		for _, release := range releases {
+			name, version := splitChartRef(release.Component.Release.Chart)
+			pinned, found := p.env.ChartVersions[name]
+			if !found || isLocalChartRef(name) {
+				continue
+			}
+
+			release.Component.Release.Chart = fmt.Sprintf("%s:%s", name, pinned)
+			p.logger.WithFields(log.Fields{"namespace": ns, "release": release.Component.Name}).
+				Debugf("Chart '%s' version '%s' is pinned to '%s'", name, version, pinned)
+		}
+	}
+}
+
 // renameNamespaces execute the rename of namespaces by passing a method along.
 func (p *Plan) renameNamespaces() {
 	p.logger.Infof("Renaming namespaces...")
 	p.envCtx.RenameNamespaces(func(ns string) string {
-		name := ns
-		if p.env.Transform.NamespacePrefix != "" || p.env.Transform.NamespaceSuffix != "" {
-			name = fmt.Sprintf("%s%s%s",
-				p.env.Transform.NamespacePrefix, ns, p.env.Transform.NamespaceSuffix,
-			)
-		}
+		name := p.env.TargetNamespace(ns)
 		// saving original namespace name
 		p.OriginalNs[name] = ns
 		return name
@@ -171,6 +188,9 @@ func (p *Plan) skipFile(file string) (bool, error) {
 
 // NewPlan creates a new Plan type instance.
 func NewPlan(env *Environment, namespaces []string, ctx *Context) *Plan {
+	envCtx := NewContext()
+	envCtx.FileKinds = ctx.FileKinds
+
 	return &Plan{
 		logger: log.WithFields(log.Fields{
 			"type": "plan", "env": env.Name, "namespaces": namespaces,
@@ -178,7 +198,7 @@ func NewPlan(env *Environment, namespaces []string, ctx *Context) *Plan {
 		env:        env,
 		namespaces: namespaces,
 		ctx:        ctx,
-		envCtx:     NewContext(),
+		envCtx:     envCtx,
 		OriginalNs: make(map[string]string),
 	}
 }
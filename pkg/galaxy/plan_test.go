@@ -42,6 +42,7 @@ func TestPlanContextForEnvironment(t *testing.T) {
 		"ns1-d": {
 			"../../test/namespaces/ns1/app1.yaml",
 			"../../test/namespaces/ns1/app2@d.yaml",
+			"../../test/namespaces/ns1/app4_with_landscaper_secret@d.yaml",
 			"../../test/namespaces/ns1/ingress-secret.yaml",
 		},
 	}
@@ -51,3 +52,22 @@ func TestPlanContextForEnvironment(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, expected, ctx.GetNamespaceFilesMap())
 }
+
+func TestPlanPinChartVersions(t *testing.T) {
+	dotGalaxy, _ := NewDotGalaxy("../../test/galaxy.yaml")
+	env, _ := dotGalaxy.GetEnvironment("dev")
+	env.ChartVersions = map[string]string{"stable/grafana": "~3.4"}
+	ctx := NewContext()
+	baseDir := path.Join(dotGalaxy.Spec.Namespaces.BaseDir, "ns1")
+
+	ctx.InspectDir("ns1", baseDir, dotGalaxy.Spec.Namespaces.Extensions)
+
+	envCtx, err := NewPlan(env, []string{}, ctx).ContextForEnvironment()
+	assert.Nil(t, err)
+
+	for _, release := range envCtx.Releases["ns1-d"] {
+		assert.Equal(t, "stable/grafana:~3.4", release.Component.Release.Chart)
+	}
+	// original context is not affected
+	assert.Equal(t, "stable/grafana:3.3.0", ctx.Releases["ns1"][0].Component.Release.Chart)
+}
@@ -0,0 +1,181 @@
+package galaxy
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// policyResources namespace policy kinds supported, and the respective API resource.
+var policyResources = map[string]schema.GroupVersionResource{
+	"ResourceQuota": {Group: "", Version: "v1", Resource: "resourcequotas"},
+	"LimitRange":    {Group: "", Version: "v1", Resource: "limitranges"},
+	"NetworkPolicy": {Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies"},
+	"RoleBinding":   {Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "rolebindings"},
+}
+
+// PolicyHandler applies namespace policy manifests to the target namespace, tagging them with the
+// same ownership labels and annotations employed on secrets, and prune the ones no longer planned.
+type PolicyHandler struct {
+	logger  *log.Entry        // logger
+	client  dynamic.Interface // kubernetes dynamic client
+	env     string            // environment name
+	baseDir string            // namespaces base directory
+	ctxs    []*Context        // slice of context instances
+	dryRun  bool              // dry-run flag
+}
+
+// Apply planned policies on namespace, reporting if each policy is created, updated or unchanged
+// based on manifest hash.
+func (p *PolicyHandler) Apply(ns string) error {
+	for _, ctx := range p.ctxs {
+		for _, policy := range ctx.Policies[ns] {
+			if err := p.apply(ns, policy); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// apply a single policy manifest.
+func (p *PolicyHandler) apply(ns string, policy PolicyManifest) error {
+	var existing *unstructured.Unstructured
+	var err error
+
+	obj := policy.Object.DeepCopy()
+	obj.SetNamespace(ns)
+	obj.SetLabels(mergeStringMaps(obj.GetLabels(), map[string]string{
+		ManagedByLabel:   ManagedByValue,
+		EnvironmentLabel: p.env,
+	}))
+	hash := fmt.Sprintf("%x", sha256.Sum256(readFile(policy.File)))
+	obj.SetAnnotations(mergeStringMaps(obj.GetAnnotations(), map[string]string{
+		SourceFileAnnotation:   relativePath(p.baseDir, policy.File),
+		ManifestHashAnnotation: hash,
+	}))
+
+	logger := p.logger.WithFields(log.Fields{
+		"namespace": ns, "kind": obj.GetKind(), "name": obj.GetName(), "file": policy.File,
+	})
+	client := p.client.Resource(policyResources[obj.GetKind()]).Namespace(ns)
+
+	if existing, err = client.Get(obj.GetName(), metav1.GetOptions{}); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		if p.dryRun {
+			logger.Info("DRY-RUN: Policy would be created.")
+			return nil
+		}
+		logger.Info("Creating policy...")
+		_, err = client.Create(obj)
+		return err
+	}
+
+	if existing.GetAnnotations()[ManifestHashAnnotation] == hash {
+		logger.Info("Policy is unchanged.")
+		return nil
+	}
+	if p.dryRun {
+		logger.Infof("DRY-RUN: Policy would be updated (hash '%s' to '%s')",
+			existing.GetAnnotations()[ManifestHashAnnotation], hash)
+		return nil
+	}
+	logger.Info("Updating policy...")
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	_, err = client.Update(obj)
+	return err
+}
+
+// Prune policies on namespace, owned by Galaxy in the same environment, and not planned anymore.
+func (p *PolicyHandler) Prune(ns string) error {
+	var kinds []string
+
+	planned := plannedPolicies(p.ctxs, ns)
+	selector := labels.SelectorFromSet(labels.Set{
+		ManagedByLabel:   ManagedByValue,
+		EnvironmentLabel: p.env,
+	}).Add(manifestsExcluded())
+
+	logger := p.logger.WithFields(log.Fields{"namespace": ns, "selector": selector.String()})
+	logger.Info("Looking for policies to prune...")
+
+	for kind := range policyResources {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	for _, kind := range kinds {
+		var list *unstructured.UnstructuredList
+		var err error
+
+		client := p.client.Resource(policyResources[kind]).Namespace(ns)
+		if list, err = client.List(metav1.ListOptions{LabelSelector: selector.String()}); err != nil {
+			return err
+		}
+
+		for _, item := range list.Items {
+			name := fmt.Sprintf("%s/%s", kind, item.GetName())
+			if _, found := planned[name]; found {
+				continue
+			}
+			if p.dryRun {
+				logger.Infof("DRY-RUN: Policy '%s' would be pruned (file '%s')",
+					name, item.GetAnnotations()[SourceFileAnnotation])
+				continue
+			}
+			logger.Infof("Pruning policy '%s' (file '%s')",
+				name, item.GetAnnotations()[SourceFileAnnotation])
+			if err = client.Delete(item.GetName(), &metav1.DeleteOptions{}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// plannedPolicies map of policies, as "kind/name", and the manifest file declaring them.
+func plannedPolicies(ctxs []*Context, ns string) map[string]string {
+	planned := make(map[string]string)
+	for _, ctx := range ctxs {
+		for _, policy := range ctx.Policies[ns] {
+			planned[fmt.Sprintf("%s/%s", policy.Object.GetKind(), policy.Object.GetName())] = policy.File
+		}
+	}
+	return planned
+}
+
+// mergeStringMaps returns a new map with entries of base, overwritten by entries of overlay.
+func mergeStringMaps(base, overlay map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(overlay))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range overlay {
+		merged[k] = v
+	}
+	return merged
+}
+
+// NewPolicyHandler instantiate namespace policies handler for environment, source file annotations
+// are relative to namespaces base directory.
+func NewPolicyHandler(
+	client dynamic.Interface, env, baseDir string, ctxs []*Context, dryRun bool) *PolicyHandler {
+	return &PolicyHandler{
+		logger:  log.WithFields(log.Fields{"type": "policyHandler", "env": env, "dryRun": dryRun}),
+		client:  client,
+		env:     env,
+		baseDir: baseDir,
+		ctxs:    ctxs,
+		dryRun:  dryRun,
+	}
+}
@@ -0,0 +1,52 @@
+package galaxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestPolicyHandler(t *testing.T) {
+	baseDir := "../../test/namespaces"
+	ctx := NewContext()
+	err := ctx.AddFile("ns2-t", "../../test/namespaces/ns2/quota.yaml")
+	assert.Nil(t, err)
+
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	quotas := client.Resource(policyResources["ResourceQuota"]).Namespace("ns2-t")
+
+	// dry-run does not change the cluster
+	p := NewPolicyHandler(client, "tst", baseDir, []*Context{ctx}, true)
+	assert.Nil(t, p.Apply("ns2-t"))
+	_, err = quotas.Get("quota", metav1.GetOptions{})
+	assert.NotNil(t, err)
+
+	p = NewPolicyHandler(client, "tst", baseDir, []*Context{ctx}, false)
+	assert.Nil(t, p.Apply("ns2-t"))
+	quota, err := quotas.Get("quota", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "ns2-t", quota.GetNamespace())
+	assert.Equal(t, ManagedByValue, quota.GetLabels()[ManagedByLabel])
+	assert.Equal(t, "tst", quota.GetLabels()[EnvironmentLabel])
+	assert.Equal(t, "ns2/quota.yaml", quota.GetAnnotations()[SourceFileAnnotation])
+	assert.NotEmpty(t, quota.GetAnnotations()[ManifestHashAnnotation])
+
+	// applying again leaves policy unchanged
+	assert.Nil(t, p.Apply("ns2-t"))
+	assert.Len(t, client.Actions(), 6)
+}
+
+func TestPolicyHandlerPlannedPolicies(t *testing.T) {
+	ctx := NewContext()
+	err := ctx.AddFile("ns2-t", "../../test/namespaces/ns2/quota.yaml")
+	assert.Nil(t, err)
+
+	planned := plannedPolicies([]*Context{ctx}, "ns2-t")
+	assert.Equal(t, map[string]string{
+		"ResourceQuota/quota": "../../test/namespaces/ns2/quota.yaml",
+	}, planned)
+	assert.Empty(t, plannedPolicies([]*Context{ctx}, "ns1-t"))
+}
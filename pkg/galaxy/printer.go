@@ -21,6 +21,15 @@ type actOnSecret func(ns string, secret SecretManifest)
 // actOnRelease to be executed against each release entry.
 type actOnRelease func(ns string, release Release)
 
+// actOnPolicy to be executed against each policy entry.
+type actOnPolicy func(ns string, policy PolicyManifest)
+
+// actOnManifest to be executed against each kubernetes manifest entry.
+type actOnManifest func(ns string, manifest Manifest)
+
+// actOnOverlay to be executed against each kustomize overlay entry.
+type actOnOverlay func(ns string, overlay Overlay)
+
 // Tree formated version of secrets and releases.
 func (p *Printer) Tree() string {
 	t := treeprint.New()
@@ -55,6 +64,39 @@ func (p *Printer) Tree() string {
 				release.Component.Name, release.Component.Release.Version,
 			))
 		})
+
+		p.loopPolicies(ctx, func(ns string, policy PolicyManifest) {
+			if _, exists := branches[ns]; !exists {
+				branches[ns] = trunk[env].AddBranch(ns)
+			}
+
+			branch := branches[ns].AddBranch(fmt.Sprintf("%s (%s)",
+				policy.File, policy.Object.GetKind(),
+			))
+			branch.AddNode(policy.Object.GetName())
+		})
+
+		p.loopManifests(ctx, func(ns string, manifest Manifest) {
+			if _, exists := branches[ns]; !exists {
+				branches[ns] = trunk[env].AddBranch(ns)
+			}
+
+			branch := branches[ns].AddBranch(fmt.Sprintf("%s (manifest)", manifest.File))
+			for _, obj := range manifest.Objects {
+				branch.AddNode(fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName()))
+			}
+		})
+
+		p.loopOverlays(ctx, func(ns string, overlay Overlay) {
+			if _, exists := branches[ns]; !exists {
+				branches[ns] = trunk[env].AddBranch(ns)
+			}
+
+			branch := branches[ns].AddBranch(fmt.Sprintf("%s (overlay)", overlay.Dir))
+			for _, obj := range overlay.Objects {
+				branch.AddNode(fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName()))
+			}
+		})
 		return nil
 	})
 
@@ -86,6 +128,40 @@ func (p *Printer) Table() string {
 				release.File,
 			))
 		})
+		p.loopPolicies(ctx, func(ns string, policy PolicyManifest) {
+			lines = append(lines, fmt.Sprintf("%s | %s | %s | %s | %s | %s",
+				env,
+				ns,
+				"policy",
+				fmt.Sprintf("%s/%s", policy.Object.GetKind(), policy.Object.GetName()),
+				policy.Object.GetAPIVersion(),
+				policy.File,
+			))
+		})
+		p.loopManifests(ctx, func(ns string, manifest Manifest) {
+			for _, obj := range manifest.Objects {
+				lines = append(lines, fmt.Sprintf("%s | %s | %s | %s | %s | %s",
+					env,
+					ns,
+					"manifest",
+					fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName()),
+					obj.GetAPIVersion(),
+					manifest.File,
+				))
+			}
+		})
+		p.loopOverlays(ctx, func(ns string, overlay Overlay) {
+			for _, obj := range overlay.Objects {
+				lines = append(lines, fmt.Sprintf("%s | %s | %s | %s | %s | %s",
+					env,
+					ns,
+					"overlay",
+					fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName()),
+					obj.GetAPIVersion(),
+					overlay.Dir,
+				))
+			}
+		})
 		return nil
 	})
 	return columnize.SimpleFormat(lines)
@@ -121,6 +197,33 @@ func (p *Printer) loopReleases(ctx *Context, fn actOnRelease) {
 	}
 }
 
+// loopPolicies present in informed data.
+func (p *Printer) loopPolicies(ctx *Context, fn actOnPolicy) {
+	for ns, policies := range ctx.Policies {
+		for _, policy := range policies {
+			fn(ns, policy)
+		}
+	}
+}
+
+// loopManifests present in informed data.
+func (p *Printer) loopManifests(ctx *Context, fn actOnManifest) {
+	for ns, manifests := range ctx.Manifests {
+		for _, manifest := range manifests {
+			fn(ns, manifest)
+		}
+	}
+}
+
+// loopOverlays present in informed data.
+func (p *Printer) loopOverlays(ctx *Context, fn actOnOverlay) {
+	for ns, overlays := range ctx.Overlays {
+		for _, overlay := range overlays {
+			fn(ns, overlay)
+		}
+	}
+}
+
 // formatSecretTypes format types found in secret manifest.
 func (p *Printer) formatSecretTypes(secret SecretManifest) string {
 	var types []string
@@ -0,0 +1,233 @@
+package galaxy
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	log "github.com/sirupsen/logrus"
+)
+
+// releaseFieldRe matches a field directly under release block, capturing indentation and key, the
+// optional quote, the value, and the remaining of the line, as in comments.
+var releaseFieldRe = regexp.MustCompile(`^(\s+(chart|version):\s*)(["']?)([^"'#\s]*)(["']?)(.*)$`)
+
+// Promotion release file rewritten to match chart and release version of source environment.
+type Promotion struct {
+	Namespace  string // original namespace name
+	Release    string // release name, as declared on release file
+	SourceFile string // release file on source environment
+	TargetFile string // release file rewritten on target environment
+	Diff       string // unified diff of target file
+}
+
+// Promoter copies chart and release versions from releases on source environment into the release
+// files of target environment, changing only the lines holding those values.
+type Promoter struct {
+	logger     *log.Entry   // logger
+	from       *Environment // source environment
+	to         *Environment // target environment
+	ctx        *Context     // context with all namespaces, not planned
+	namespaces []string     // target namespaces, optional
+	release    string       // release name, optional
+	dryRun     bool         // dry-run flag
+	Promotions []Promotion  // release files promoted
+}
+
+// Promote releases present on both environments, when they are declared on distinct files and
+// chart or release version differ. On dry-run target files are not written.
+func (p *Promoter) Promote() error {
+	var source, target map[string]Release
+	var keys []string
+	var err error
+
+	if source, err = p.envReleases(p.from); err != nil {
+		return err
+	}
+	if target, err = p.envReleases(p.to); err != nil {
+		return err
+	}
+	for key := range target {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		dst := target[key]
+		logger := p.logger.WithFields(log.Fields{"namespace": dst.Namespace, "file": dst.File})
+
+		src, found := source[key]
+		if !found {
+			logger.Infof("Release '%s' is not found on source environment", dst.Component.Name)
+			continue
+		}
+		if src.File == dst.File {
+			logger.Debugf("Release '%s' file is shared by both environments", dst.Component.Name)
+			continue
+		}
+		if err = p.promote(src, dst); err != nil {
+			return fmt.Errorf("file '%s': %s", dst.File, err)
+		}
+	}
+	return nil
+}
+
+// promote rewrite target release file with chart and release version of source release.
+func (p *Promoter) promote(src, dst Release) error {
+	var payload []byte
+	var promoted, diff string
+	var err error
+
+	if payload, err = ioutil.ReadFile(dst.File); err != nil {
+		return err
+	}
+	original := string(payload)
+	if promoted, err = rewriteReleaseFields(original, map[string]string{
+		"chart":   src.Component.Release.Chart,
+		"version": src.Component.Release.Version,
+	}); err != nil {
+		return err
+	}
+	if promoted == original {
+		p.logger.WithField("file", dst.File).Info("Release is already promoted.")
+		return nil
+	}
+
+	if diff, err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(original),
+		B:        difflib.SplitLines(promoted),
+		FromFile: dst.File,
+		ToFile:   dst.File,
+		Context:  3,
+	}); err != nil {
+		return err
+	}
+	p.Promotions = append(p.Promotions, Promotion{
+		Namespace:  dst.Namespace,
+		Release:    dst.Component.Name,
+		SourceFile: src.File,
+		TargetFile: dst.File,
+		Diff:       diff,
+	})
+
+	if p.dryRun {
+		p.logger.WithField("file", dst.File).Info("DRY-RUN: Release file would be rewritten.")
+		return nil
+	}
+	info, err := os.Stat(dst.File)
+	if err != nil {
+		return err
+	}
+	p.logger.WithField("file", dst.File).Info("Rewriting release file...")
+	return ioutil.WriteFile(dst.File, []byte(promoted), info.Mode())
+}
+
+// envReleases releases applicable on environment, keyed by original namespace and release name. A
+// release declared more than once on environment is an error, since target file is ambiguous.
+func (p *Promoter) envReleases(env *Environment) (map[string]Release, error) {
+	releases := make(map[string]Release)
+
+	plan := NewPlan(env, p.namespaces, p.ctx)
+	if err := plan.filter(); err != nil {
+		return nil, err
+	}
+	for ns, nsReleases := range plan.envCtx.Releases {
+		for _, release := range nsReleases {
+			if p.release != "" && release.Component.Name != p.release {
+				continue
+			}
+			key := fmt.Sprintf("%s/%s", ns, release.Component.Name)
+			if existing, found := releases[key]; found {
+				return nil, fmt.Errorf("release '%s' is declared on files '%s' and '%s'",
+					key, existing.File, release.File)
+			}
+			releases[key] = release
+		}
+	}
+	return releases, nil
+}
+
+// rewriteReleaseFields replace the value of fields directly under top level "release" key, keeping
+// the remaining of the document, including comments and quoting, untouched. When no field is found,
+// as in flow style release blocks, an error is returned.
+func rewriteReleaseFields(payload string, values map[string]string) (string, error) {
+	var indent string
+	var rewritten int
+
+	inRelease := false
+	lines := strings.Split(payload, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			inRelease = strings.HasPrefix(trimmed, "release:")
+			indent = ""
+			continue
+		}
+		if !inRelease {
+			continue
+		}
+
+		lineIndent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if indent == "" {
+			indent = lineIndent
+		}
+		if lineIndent != indent {
+			continue
+		}
+
+		match := releaseFieldRe.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		value, found := values[match[2]]
+		if !found || value == "" {
+			continue
+		}
+		lines[i] = fmt.Sprintf("%s%s%s%s%s", match[1], match[3], value, match[5], match[6])
+		rewritten++
+	}
+	if rewritten == 0 {
+		return "", fmt.Errorf("no chart or version field found under release block")
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// PromotionsDiff concatenate the diff of each promotion.
+func PromotionsDiff(promotions []Promotion) string {
+	var diffs []string
+
+	for _, promotion := range promotions {
+		diffs = append(diffs, promotion.Diff)
+	}
+	return strings.Join(diffs, "\n")
+}
+
+// NewPromoter instantiate a promoter of releases between environments, optionally narrowed down to
+// namespaces and a single release name.
+func NewPromoter(
+	from, to *Environment,
+	ctx *Context,
+	namespaces []string,
+	release string,
+	dryRun bool,
+) *Promoter {
+	return &Promoter{
+		logger: log.WithFields(log.Fields{
+			"type": "promoter", "from": from.Name, "to": to.Name, "dryRun": dryRun,
+		}),
+		from:       from,
+		to:         to,
+		ctx:        ctx,
+		namespaces: namespaces,
+		release:    release,
+		dryRun:     dryRun,
+		Promotions: []Promotion{},
+	}
+}
@@ -0,0 +1,104 @@
+package galaxy
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromoterRewriteReleaseFields(t *testing.T) {
+	payload := `---
+# application
+name: app
+release:
+  # pinned by hand
+  chart: "stable/app:0.1.0"  # chart
+  version: 1.0.0
+  wait:
+    version: 9.9.9
+configuration:
+  version: 1.0.0
+`
+	expected := `---
+# application
+name: app
+release:
+  # pinned by hand
+  chart: "stable/app:0.2.0"  # chart
+  version: 1.1.0
+  wait:
+    version: 9.9.9
+configuration:
+  version: 1.0.0
+`
+	values := map[string]string{"chart": "stable/app:0.2.0", "version": "1.1.0"}
+	rewritten, err := rewriteReleaseFields(payload, values)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, rewritten)
+
+	// flow style is not supported
+	_, err = rewriteReleaseFields("release: {chart: \"stable/app:0.1.0\", version: 1.0.0}\n", values)
+	assert.NotNil(t, err)
+}
+
+func TestPromoterPromote(t *testing.T) {
+	SetLogLevel("trace")
+
+	dir, err := ioutil.TempDir("", "galaxy-promoter")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	write := func(name, release, chart, version string) string {
+		file := path.Join(dir, name)
+		payload := "# " + name + "\nname: " + release + "\nrelease:\n  chart: " + chart + "\n" +
+			"  version: " + version + " # release\n"
+		assert.Nil(t, ioutil.WriteFile(file, []byte(payload), 0644))
+		return file
+	}
+	write("app@d.yaml", "app", "stable/app:0.2.0", "1.1.0")
+	target := write("app@t.yaml", "app", "stable/app:0.1.0", "1.0.0")
+	write("shared.yaml", "shared", "stable/shared:0.1.0", "1.0.0")
+
+	ctx := NewContext()
+	assert.Nil(t, ctx.InspectDir("ns1", dir, []string{"yaml"}))
+
+	dev := &Environment{Name: "dev", FileSuffixes: []string{"d", ""}}
+	tst := &Environment{Name: "tst", FileSuffixes: []string{"t", ""}}
+
+	// dry-run does not write files
+	p := NewPromoter(dev, tst, ctx, []string{}, "", true)
+	assert.Nil(t, p.Promote())
+	assert.Len(t, p.Promotions, 1)
+	assert.Contains(t, p.Promotions[0].Diff, "+  chart: stable/app:0.2.0")
+	assert.Contains(t, string(readFile(target)), "stable/app:0.1.0")
+
+	p = NewPromoter(dev, tst, ctx, []string{}, "app", false)
+	assert.Nil(t, p.Promote())
+	assert.Len(t, p.Promotions, 1)
+	assert.Equal(t, "# app@t.yaml\nname: app\nrelease:\n  chart: stable/app:0.2.0\n"+
+		"  version: 1.1.0 # release\n", string(readFile(target)))
+
+	// already promoted
+	ctx = NewContext()
+	assert.Nil(t, ctx.InspectDir("ns1", dir, []string{"yaml"}))
+	p = NewPromoter(dev, tst, ctx, []string{}, "", false)
+	assert.Nil(t, p.Promote())
+	assert.Len(t, p.Promotions, 0)
+
+	// release name not matching
+	p = NewPromoter(dev, tst, ctx, []string{}, "other", false)
+	assert.Nil(t, p.Promote())
+	assert.Len(t, p.Promotions, 0)
+
+	// release declared twice on target environment
+	write("app-copy@t.yaml", "app", "stable/app:0.1.0", "1.0.0")
+	ctx = NewContext()
+	assert.Nil(t, ctx.InspectDir("ns1", dir, []string{"yaml"}))
+	p = NewPromoter(dev, tst, ctx, []string{}, "", false)
+	err = p.Promote()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "app-copy@t.yaml")
+}
@@ -0,0 +1,349 @@
+package galaxy
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ryanuber/columnize"
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kubernetes/pkg/apis/apps"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset"
+)
+
+const (
+	// SecretsAnnotation component annotation listing secret names consumed by release, comma
+	// separated, in addition to the names found in release configuration.
+	SecretsAnnotation = "galaxy/secrets"
+	// RestartedAtAnnotation pod template annotation employed to trigger a rolling restart.
+	RestartedAtAnnotation = "galaxy/restartedAt"
+)
+
+// releaseLabels labels employed by charts to identify workloads of a release.
+var releaseLabels = []string{"release", "app.kubernetes.io/instance"}
+
+// Restart records a workload restarted because the secrets it consumes have changed.
+type Restart struct {
+	Namespace string   // kubernetes namespace
+	Kind      string   // workload kind
+	Name      string   // workload name
+	Release   string   // release name
+	Secrets   []string // changed secrets consumed by release
+}
+
+// Restarter triggers a rolling restart of Deployments and StatefulSets belonging to releases that
+// consume secrets changed during apply. On dry-run, changes are predicted from secret source.
+type Restarter struct {
+	logger    *log.Entry                   // logger
+	client    clientset.Interface          // kubernetes api client
+	env       string                       // environment name
+	ctxs      []*Context                   // slice of context instances
+	source    SecretSource                 // secret source, employed to predict changes on dry-run
+	dryRun    bool                         // dry-run flag
+	checksums map[string]map[string]string // secret checksums per namespace, before apply
+	Restarts  []Restart                    // restarted workloads
+}
+
+// Snapshot planned secrets checksums on namespace, must be called before applying secrets.
+func (r *Restarter) Snapshot(ns string) error {
+	var err error
+
+	if r.checksums[ns], err = r.secretChecksums(ns); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Changed compare planned secrets checksums with the snapshot, returning the names of existing
+// secrets that have changed. On dry-run secrets are not applied, so checksums are calculated from
+// the planned secret data instead.
+func (r *Restarter) Changed(ns string) ([]string, error) {
+	var checksums map[string]string
+	var changed []string
+	var err error
+
+	if r.dryRun {
+		checksums, err = r.plannedChecksums(ns)
+	} else {
+		checksums, err = r.secretChecksums(ns)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for name, before := range r.checksums[ns] {
+		if after, found := checksums[name]; found && before != after {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// secretChecksums calculate the checksum of planned secrets on namespace, secrets that are not
+// found are skipped.
+func (r *Restarter) secretChecksums(ns string) (map[string]string, error) {
+	var secret *core.Secret
+	var err error
+
+	checksums := make(map[string]string)
+	for name := range plannedSecrets(r.ctxs, ns) {
+		if secret, err = r.client.Core().Secrets(ns).Get(name, metav1.GetOptions{}); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		checksums[name] = dataChecksum(secret.Data)
+	}
+	return checksums, nil
+}
+
+// plannedChecksums calculate the checksum existing secrets on namespace would have after apply, by
+// overwriting secret data with the keys declared in secret manifests, read from secret source.
+func (r *Restarter) plannedChecksums(ns string) (map[string]string, error) {
+	var secret *core.Secret
+	var sourceData map[string][]byte
+	var err error
+
+	checksums := make(map[string]string)
+	if r.source == nil {
+		r.logger.WithField("namespace", ns).Warn("Secret source is not available, " +
+			"workload restarts are not predicted on dry-run.")
+		return r.checksums[ns], nil
+	}
+	for _, ctx := range r.ctxs {
+		for _, manifest := range ctx.Secrets[ns] {
+			for name, secretData := range manifest.Manifest.Secrets {
+				if secret, err = r.client.Core().Secrets(ns).Get(name, metav1.GetOptions{}); err != nil {
+					if apierrors.IsNotFound(err) {
+						continue
+					}
+					return nil, err
+				}
+				if sourceData, err = r.source.Read(secretData.Path); err != nil {
+					return nil, err
+				}
+
+				data := make(map[string][]byte)
+				for key, value := range secret.Data {
+					data[key] = value
+				}
+				for _, item := range secretData.Data {
+					if value, found := sourceData[item.Name]; found {
+						data[item.Name] = value
+					}
+				}
+				checksums[name] = dataChecksum(data)
+			}
+		}
+	}
+	return checksums, nil
+}
+
+// dataChecksum checksum of secret data, keys are sorted.
+func dataChecksum(data map[string][]byte) string {
+	var keys []string
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		hash.Write([]byte(key))
+		hash.Write(data[key])
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
+
+// Restart workloads of releases on namespace consuming informed secrets.
+func (r *Restarter) Restart(ns string, secrets []string) error {
+	var err error
+
+	if len(secrets) == 0 {
+		return nil
+	}
+
+	for _, ctx := range r.ctxs {
+		for _, release := range ctx.Releases[ns] {
+			var consumed []string
+
+			for _, name := range secrets {
+				if releaseConsumesSecret(release.Component, r.env, name) {
+					consumed = append(consumed, name)
+				}
+			}
+			if len(consumed) == 0 {
+				continue
+			}
+			if err = r.restartRelease(ns, release.Component.Name, consumed); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// restartRelease patch pod template of Deployments and StatefulSets labeled with release name.
+func (r *Restarter) restartRelease(ns, release string, secrets []string) error {
+	var deployments *extensions.DeploymentList
+	var statefulSets *apps.StatefulSetList
+	var err error
+
+	logger := r.logger.WithFields(log.Fields{"namespace": ns, "release": release})
+	patch := []byte(fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{"%s":"%s"}}}}}`,
+		RestartedAtAnnotation, time.Now().Format(time.RFC3339)))
+
+	restarted := make(map[string]bool)
+	for _, label := range releaseLabels {
+		opts := metav1.ListOptions{
+			LabelSelector: labels.SelectorFromSet(labels.Set{label: release}).String(),
+		}
+
+		if deployments, err = r.client.Extensions().Deployments(ns).List(opts); err != nil {
+			return err
+		}
+		for _, deployment := range deployments.Items {
+			if restarted["Deployment/"+deployment.Name] {
+				continue
+			}
+			restarted["Deployment/"+deployment.Name] = true
+			if err = r.restart(logger, ns, "Deployment", deployment.Name, release, secrets,
+				func() error {
+					_, err := r.client.Extensions().Deployments(ns).Patch(
+						deployment.Name, types.StrategicMergePatchType, patch)
+					return err
+				}); err != nil {
+				return err
+			}
+		}
+
+		if statefulSets, err = r.client.Apps().StatefulSets(ns).List(opts); err != nil {
+			return err
+		}
+		for _, statefulSet := range statefulSets.Items {
+			if restarted["StatefulSet/"+statefulSet.Name] {
+				continue
+			}
+			restarted["StatefulSet/"+statefulSet.Name] = true
+			if err = r.restart(logger, ns, "StatefulSet", statefulSet.Name, release, secrets,
+				func() error {
+					_, err := r.client.Apps().StatefulSets(ns).Patch(
+						statefulSet.Name, types.StrategicMergePatchType, patch)
+					return err
+				}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(restarted) == 0 {
+		logger.Warnf("No workloads found for release, consuming secrets '%v'", secrets)
+	}
+	return nil
+}
+
+// restart execute patch function, honoring dry-run, and record the restart.
+func (r *Restarter) restart(
+	logger *log.Entry, ns, kind, name, release string, secrets []string, patchFn func() error,
+) error {
+	logger = logger.WithFields(log.Fields{"kind": kind, "name": name, "secrets": secrets})
+	if r.dryRun {
+		logger.Info("DRY-RUN: Workload would be restarted.")
+	} else {
+		logger.Info("Restarting workload...")
+		if err := patchFn(); err != nil {
+			return err
+		}
+	}
+
+	r.Restarts = append(r.Restarts, Restart{
+		Namespace: ns, Kind: kind, Name: name, Release: release, Secrets: secrets,
+	})
+	return nil
+}
+
+// releaseConsumesSecret checks if component references secret name, via annotation or as a value
+// in configuration, including environment specific configuration.
+func releaseConsumesSecret(component *Component, env, secret string) bool {
+	if names, found := component.Annotations[SecretsAnnotation]; found {
+		if stringSliceContains(splitOnComma(strings.Replace(names, " ", "", -1)), secret) {
+			return true
+		}
+	}
+	if valueReferences(map[string]interface{}(component.Configuration), secret) {
+		return true
+	}
+	if cfg, found := component.Environments[env]; found {
+		return valueReferences(map[string]interface{}(cfg), secret)
+	}
+	return false
+}
+
+// valueReferences walk through the value recursively, looking for a string equal to name.
+func valueReferences(value interface{}, name string) bool {
+	switch value := value.(type) {
+	case string:
+		return value == name
+	case map[string]interface{}:
+		for _, v := range value {
+			if valueReferences(v, name) {
+				return true
+			}
+		}
+	case map[interface{}]interface{}:
+		for _, v := range value {
+			if valueReferences(v, name) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, v := range value {
+			if valueReferences(v, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RestartsTable format restarts as a table.
+func RestartsTable(restarts []Restart) string {
+	lines := []string{"NAMESPACE | KIND | NAME | RELEASE | SECRETS"}
+	for _, restart := range restarts {
+		lines = append(lines, fmt.Sprintf("%s | %s | %s | %s | %s",
+			restart.Namespace, restart.Kind, restart.Name, restart.Release,
+			strings.Join(restart.Secrets, ", "),
+		))
+	}
+	return columnize.SimpleFormat(lines)
+}
+
+// NewRestarter instantiate a new workload restarter for environment, secret source is employed to
+// predict changes on dry-run.
+func NewRestarter(
+	client clientset.Interface,
+	env string,
+	ctxs []*Context,
+	source SecretSource,
+	dryRun bool,
+) *Restarter {
+	return &Restarter{
+		logger:    log.WithFields(log.Fields{"type": "restarter", "env": env, "dryRun": dryRun}),
+		client:    client,
+		env:       env,
+		ctxs:      ctxs,
+		source:    source,
+		dryRun:    dryRun,
+		checksums: make(map[string]map[string]string),
+	}
+}
@@ -0,0 +1,135 @@
+package galaxy
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ldsc "github.com/Eneco/landscaper/pkg/landscaper"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/kubernetes/pkg/apis/apps"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset/fake"
+)
+
+func TestRestarterReleaseConsumesSecret(t *testing.T) {
+	component := &Component{
+		Annotations:   map[string]string{SecretsAnnotation: "a, b"},
+		Configuration: ldsc.Configuration{"tls": map[interface{}]interface{}{"secretName": "c"}},
+		Environments: ldsc.Configurations{
+			"dev": ldsc.Configuration{"volumes": []interface{}{"d"}},
+		},
+	}
+
+	assert.True(t, releaseConsumesSecret(component, "dev", "a"))
+	assert.True(t, releaseConsumesSecret(component, "dev", "b"))
+	assert.True(t, releaseConsumesSecret(component, "dev", "c"))
+	assert.True(t, releaseConsumesSecret(component, "dev", "d"))
+	assert.False(t, releaseConsumesSecret(component, "tst", "d"))
+	assert.False(t, releaseConsumesSecret(component, "dev", "e"))
+}
+
+func TestRestarterRestart(t *testing.T) {
+	ns := "ns1-d"
+	ctx := NewContext()
+	err := ctx.AddFile(ns, "../../test/namespaces/ns1/ingress-secret.yaml")
+	assert.Nil(t, err)
+	ctx.Releases[ns] = []Release{{
+		Namespace: ns,
+		Component: &Component{
+			Name:          "d-ns1-app1",
+			Configuration: ldsc.Configuration{"ingress": map[string]interface{}{"tls": "ingress"}},
+		},
+	}, {
+		Namespace: ns,
+		Component: &Component{Name: "d-ns1-app2"},
+	}}
+
+	meta := func(name, release string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: ns, Labels: map[string]string{"release": release}}
+	}
+	kube := fake.NewSimpleClientset(
+		&core.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "ingress", Namespace: ns},
+			Data:       map[string][]byte{"tls.crt": []byte("certificate")},
+		},
+		&extensions.Deployment{ObjectMeta: meta("app1", "d-ns1-app1")},
+		&apps.StatefulSet{ObjectMeta: meta("app1-db", "d-ns1-app1")},
+		&extensions.Deployment{ObjectMeta: meta("app2", "d-ns1-app2")},
+	)
+
+	r := NewRestarter(kube, "dev", []*Context{ctx}, nil, false)
+	assert.Nil(t, r.Snapshot(ns))
+
+	changed, err := r.Changed(ns)
+	assert.Nil(t, err)
+	assert.Empty(t, changed)
+
+	_, err = kube.Core().Secrets(ns).Update(&core.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingress", Namespace: ns},
+		Data:       map[string][]byte{"tls.crt": []byte("new-certificate")},
+	})
+	assert.Nil(t, err)
+
+	changed, err = r.Changed(ns)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ingress"}, changed)
+
+	assert.Nil(t, r.Restart(ns, changed))
+	assert.Len(t, r.Restarts, 2)
+
+	var patched []string
+	for _, action := range kube.Actions() {
+		if patch, ok := action.(clienttesting.PatchAction); ok {
+			assert.Contains(t, string(patch.GetPatch()), RestartedAtAnnotation)
+			patched = append(patched, patch.GetResource().Resource+"/"+patch.GetName())
+		}
+	}
+	assert.Equal(t, []string{"deployments/app1", "statefulsets/app1-db"}, patched)
+
+	table := RestartsTable(r.Restarts)
+	t.Logf("Restarts:\n%s", table)
+	assert.Contains(t, table, "StatefulSet")
+}
+
+func TestRestarterChangedDryRun(t *testing.T) {
+	ns := "ns1-d"
+	dir, secretDir := fakeSecretsDir(t)
+	defer os.RemoveAll(dir)
+
+	err := ioutil.WriteFile(filepath.Join(secretDir, "tls.crt"), []byte("certificate"), 0600)
+	assert.Nil(t, err)
+
+	ctx := NewContext()
+	err = ctx.AddFile(ns, "../../test/namespaces/ns1/ingress-secret.yaml")
+	assert.Nil(t, err)
+	kube := fake.NewSimpleClientset(&core.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingress", Namespace: ns},
+		Data:       map[string][]byte{"tls.crt": []byte("certificate")},
+	})
+
+	// secret source contents are the same as in cluster
+	r := NewRestarter(kube, "dev", []*Context{ctx}, NewFileSecretSource(dir, "", ""), true)
+	assert.Nil(t, r.Snapshot(ns))
+	changed, err := r.Changed(ns)
+	assert.Nil(t, err)
+	assert.Empty(t, changed)
+
+	// change is predicted from secret source, while the cluster is untouched
+	err = ioutil.WriteFile(filepath.Join(secretDir, "tls.crt"), []byte("new-certificate"), 0600)
+	assert.Nil(t, err)
+	changed, err = r.Changed(ns)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ingress"}, changed)
+
+	// without secret source restarts are not predicted
+	r = NewRestarter(kube, "dev", []*Context{ctx}, nil, true)
+	assert.Nil(t, r.Snapshot(ns))
+	changed, err = r.Changed(ns)
+	assert.Nil(t, err)
+	assert.Empty(t, changed)
+}
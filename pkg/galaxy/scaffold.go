@@ -0,0 +1,234 @@
+package galaxy
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// releaseTemplate Landscaper component template for new releases.
+const releaseTemplate = `---
+name: %s
+release:
+  chart: %s
+  version: 0.0.1
+configuration: {}
+environments: {}
+`
+
+// Scaffold generates dot-galaxy file, namespace directories and release files, for new
+// repositories.
+type Scaffold struct {
+	logger   *log.Entry // logger
+	filePath string     // dot-galaxy file path
+}
+
+// InitDotGalaxy writes a new dot-galaxy file with informed environments and namespaces, and create
+// namespace directories under base directory. Each environment takes a file suffix, a namespace
+// suffix and a release prefix. Existing dot-galaxy file is not overwritten.
+func (s *Scaffold) InitDotGalaxy(baseDir string, envs, namespaces []string) error {
+	var b strings.Builder
+	var suffixes []string
+	var err error
+
+	if fileExists(s.filePath) {
+		return fmt.Errorf("dot-galaxy file already exists at '%s'", s.filePath)
+	}
+	if len(envs) == 0 {
+		return fmt.Errorf("at least one environment must be informed")
+	}
+	for _, env := range envs {
+		if env == "" {
+			return fmt.Errorf("environment name must not be empty, in '%v'", envs)
+		}
+	}
+
+	fmt.Fprintf(&b, "---\ngalaxy:\n  namespaces:\n    baseDir: %s\n", baseDir)
+	b.WriteString("    extensions:\n      - yaml\n      - yml\n    names:")
+	if len(namespaces) == 0 {
+		b.WriteString(" []")
+	}
+	b.WriteString("\n")
+	for _, ns := range namespaces {
+		fmt.Fprintf(&b, "      - %s\n", ns)
+	}
+	if suffixes, err = fileSuffixes(envs); err != nil {
+		return err
+	}
+	b.WriteString("  environments:\n")
+	for env, suffix := range suffixes {
+		fmt.Fprintf(&b, "    - name: %s\n", envs[env])
+		fmt.Fprintf(&b, "      fileSuffixes:\n        - %s\n        - \"\"\n", suffix)
+		fmt.Fprintf(&b, "      transform:\n        namespaceSuffix: -%s\n", envs[env])
+		b.WriteString("        releasePrefix: ${NAMESPACE_SUFFIX:1:1}-${NAMESPACE}-\n")
+	}
+
+	if _, err = parseDotGalaxy([]byte(b.String())); err != nil {
+		return err
+	}
+	s.logger.Infof("Writing dot-galaxy file with environments '%v'", envs)
+	if err = ioutil.WriteFile(s.filePath, []byte(b.String()), 0644); err != nil {
+		return err
+	}
+
+	for _, ns := range namespaces {
+		if err = s.mkdir(path.Join(baseDir, ns)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InitNamespace create namespace directory under base directory, and add namespace to dot-galaxy
+// names list, in place, keeping the remaining of the file untouched.
+func (s *Scaffold) InitNamespace(name string) error {
+	var dotGalaxy *DotGalaxy
+	var payload []byte
+	var err error
+
+	if payload, err = ioutil.ReadFile(s.filePath); err != nil {
+		return err
+	}
+	if dotGalaxy, err = parseDotGalaxy(payload); err != nil {
+		return err
+	}
+
+	if !stringSliceContains(dotGalaxy.ListNamespaces(), name) {
+		var updated string
+
+		if updated, err = appendNamespaceName(string(payload), name); err != nil {
+			return fmt.Errorf("file '%s': %s", s.filePath, err)
+		}
+		if _, err = parseDotGalaxy([]byte(updated)); err != nil {
+			return err
+		}
+		s.logger.Infof("Adding namespace '%s' to dot-galaxy", name)
+		if err = ioutil.WriteFile(s.filePath, []byte(updated), 0644); err != nil {
+			return err
+		}
+	}
+	return s.mkdir(path.Join(dotGalaxy.Spec.Namespaces.BaseDir, name))
+}
+
+// InitRelease writes a Landscaper component template for release on namespace directory, using
+// informed chart reference. Returns the release file path, existing files are not overwritten.
+func (s *Scaffold) InitRelease(ns, name, chart string) (string, error) {
+	var dotGalaxy *DotGalaxy
+	var dir string
+	var err error
+
+	if dotGalaxy, err = NewDotGalaxy(s.filePath); err != nil {
+		return "", err
+	}
+	if dir, err = dotGalaxy.GetNamespaceDir(ns); err != nil {
+		return "", err
+	}
+	if !isDir(dir) {
+		return "", fmt.Errorf("namespace directory is not found at '%s'", dir)
+	}
+
+	file := path.Join(dir, fmt.Sprintf("%s.yaml", name))
+	if fileExists(file) {
+		return "", fmt.Errorf("release file already exists at '%s'", file)
+	}
+	chartName, _ := splitChartRef(chart)
+	if !isLocalChartRef(chart) && len(strings.Split(chartName, "/")) != 2 {
+		return "", fmt.Errorf("chart '%s': expected chart as 'repo/name:version'", chart)
+	}
+
+	payload := []byte(fmt.Sprintf(releaseTemplate, name, chart))
+	if err = NewContext().addFileAs(ns, file, FileKindRelease, payload); err != nil {
+		return "", fmt.Errorf("release '%s': %s", name, err)
+	}
+	s.logger.Infof("Writing release file '%s'", file)
+	return file, ioutil.WriteFile(file, payload, 0644)
+}
+
+// mkdir create directory, when not present yet.
+func (s *Scaffold) mkdir(dir string) error {
+	if isDir(dir) {
+		return nil
+	}
+	s.logger.Infof("Creating directory '%s'", dir)
+	return os.MkdirAll(dir, 0755)
+}
+
+// fileSuffixes file suffix per environment, first letter of environment name, or the whole name
+// when the letter is already taken. Returns error when the suffix is still taken by another
+// environment, since their files would be mixed up.
+func fileSuffixes(envs []string) ([]string, error) {
+	var suffixes []string
+
+	for _, env := range envs {
+		suffix := env[:1]
+		if stringSliceContains(suffixes, suffix) {
+			suffix = env
+		}
+		for i, taken := range suffixes {
+			if taken == suffix {
+				return nil, fmt.Errorf("environments '%s' and '%s' would share file suffix '%s'",
+					envs[i], env, suffix)
+			}
+		}
+		suffixes = append(suffixes, suffix)
+	}
+	return suffixes, nil
+}
+
+// appendNamespaceName add namespace name as the last item of "galaxy.namespaces.names" list,
+// keeping indentation of existing items.
+func appendNamespaceName(payload, name string) (string, error) {
+	var namesIndent, itemIndent string
+
+	lines := strings.Split(payload, "\n")
+	namesAt, lastAt := -1, -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		indent := line[:len(line)-len(strings.TrimLeft(line, " "))]
+
+		if namesAt < 0 {
+			if strings.HasPrefix(trimmed, "names:") && len(indent) > 0 {
+				namesAt, lastAt, namesIndent = i, i, indent
+				if strings.TrimSpace(strings.TrimPrefix(trimmed, "names:")) == "[]" {
+					lines[i] = fmt.Sprintf("%snames:", indent)
+					break
+				}
+			}
+			continue
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if len(indent) < len(namesIndent) ||
+			(len(indent) == len(namesIndent) && !strings.HasPrefix(trimmed, "- ")) {
+			break
+		}
+		if itemIndent == "" && strings.HasPrefix(trimmed, "- ") {
+			itemIndent = indent
+		}
+		lastAt = i
+	}
+
+	if namesAt < 0 {
+		return "", fmt.Errorf("namespace names list is not found")
+	}
+	if itemIndent == "" {
+		itemIndent = namesIndent + "  "
+	}
+	item := fmt.Sprintf("%s- %s", itemIndent, name)
+
+	lines = append(lines[:lastAt+1], append([]string{item}, lines[lastAt+1:]...)...)
+	return strings.Join(lines, "\n"), nil
+}
+
+// NewScaffold instantiate scaffold for dot-galaxy file path.
+func NewScaffold(filePath string) *Scaffold {
+	return &Scaffold{
+		logger:   log.WithFields(log.Fields{"type": "scaffold", "file": filePath}),
+		filePath: filePath,
+	}
+}
@@ -0,0 +1,89 @@
+package galaxy
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScaffoldInit(t *testing.T) {
+	SetLogLevel("trace")
+
+	dir, err := ioutil.TempDir("", "galaxy-scaffold")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	filePath := path.Join(dir, ".galaxy.yaml")
+	baseDir := path.Join(dir, "namespaces")
+	s := NewScaffold(filePath)
+
+	assert.NotNil(t, s.InitDotGalaxy(baseDir, []string{"dev", ""}, []string{"ns1"}))
+	err = s.InitDotGalaxy(baseDir, []string{"dev", "d"}, []string{"ns1"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "'dev' and 'd'")
+	assert.Nil(t, s.InitDotGalaxy(baseDir, []string{"dev", "demo", "prd"}, []string{"ns1"}))
+	assert.NotNil(t, s.InitDotGalaxy(baseDir, []string{"dev"}, []string{}))
+	assert.True(t, isDir(path.Join(baseDir, "ns1")))
+
+	dotGalaxy, err := NewDotGalaxy(filePath)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"dev", "demo", "prd"}, dotGalaxy.ListEnvironments())
+	env, _ := dotGalaxy.GetEnvironment("demo")
+	assert.Equal(t, []string{"demo", ""}, env.FileSuffixes)
+	assert.Equal(t, "${NAMESPACE_SUFFIX:1:1}-${NAMESPACE}-", env.Transform.ReleasePrefix)
+
+	// namespace is added in place, only once
+	assert.Nil(t, s.InitNamespace("ns2"))
+	assert.Nil(t, s.InitNamespace("ns2"))
+	assert.True(t, isDir(path.Join(baseDir, "ns2")))
+	dotGalaxy, err = NewDotGalaxy(filePath)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ns1", "ns2"}, dotGalaxy.ListNamespaces())
+
+	file, err := s.InitRelease("ns2", "app", "stable/app:0.1.0")
+	assert.Nil(t, err)
+	ctx := NewContext()
+	assert.Nil(t, ctx.AddFile("ns2", file))
+	assert.Equal(t, "stable/app:0.1.0", ctx.Releases["ns2"][0].Component.Release.Chart)
+
+	_, err = s.InitRelease("ns2", "app", "stable/app:0.1.0")
+	assert.NotNil(t, err)
+	_, err = s.InitRelease("ns3", "app", "stable/app:0.1.0")
+	assert.NotNil(t, err)
+	_, err = s.InitRelease("ns2", "other", "app")
+	assert.NotNil(t, err)
+}
+
+func TestScaffoldAppendNamespaceName(t *testing.T) {
+	payload := `galaxy:
+  namespaces:
+    names:
+      - ns1
+      - name: ns2
+        create: true
+    # file kinds
+    fileKinds: {}
+`
+	updated, err := appendNamespaceName(payload, "ns3")
+	assert.Nil(t, err)
+	assert.Equal(t, `galaxy:
+  namespaces:
+    names:
+      - ns1
+      - name: ns2
+        create: true
+      - ns3
+    # file kinds
+    fileKinds: {}
+`, updated)
+
+	updated, err = appendNamespaceName("galaxy:\n  namespaces:\n    names: []\n", "ns1")
+	assert.Nil(t, err)
+	assert.Equal(t, "galaxy:\n  namespaces:\n    names:\n      - ns1\n", updated)
+
+	_, err = appendNamespaceName("galaxy: {}\n", "ns1")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,156 @@
+package galaxy
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+)
+
+const (
+	// SecretSourceVault secrets are read from Vault, default source.
+	SecretSourceVault = "vault"
+	// SecretSourceFile secrets are read from local files, plaintext or GPG encrypted.
+	SecretSourceFile = "file"
+)
+
+// SecretSource backend where secret data is read from, secret manifest's path is informed and the
+// secret data keys are expected back.
+type SecretSource interface {
+	Read(path string) (map[string][]byte, error)
+}
+
+// FileSecretSource reads secrets from a local directory, where the secret manifest's path is a
+// sub-directory and each file is a secret key. Files ending with ".gpg" (binary) or ".asc"
+// (armored) are OpenPGP encrypted, and the extension is not part of the key name.
+type FileSecretSource struct {
+	logger     *log.Entry // logger
+	dir        string     // base directory
+	keyRing    string     // path to gpg key-ring file, optional
+	passphrase string     // passphrase for private key or symmetric encryption
+}
+
+// Read secret files from the path sub-directory, decrypting when needed.
+func (f *FileSecretSource) Read(path string) (map[string][]byte, error) {
+	var files []os.FileInfo
+	var err error
+
+	secretDir := filepath.Join(f.dir, path)
+	logger := f.logger.WithField("secretDir", secretDir)
+	logger.Debugf("Reading secret from path '%s'", path)
+
+	if !isDir(secretDir) {
+		return nil, fmt.Errorf("no secret data found on path '%s' (directory '%s')", path, secretDir)
+	}
+	if files, err = ioutil.ReadDir(secretDir); err != nil {
+		return nil, err
+	}
+
+	data := make(map[string][]byte)
+	for _, file := range files {
+		var payload []byte
+
+		if !file.Mode().IsRegular() {
+			continue
+		}
+		if payload, err = ioutil.ReadFile(filepath.Join(secretDir, file.Name())); err != nil {
+			return nil, err
+		}
+
+		key := file.Name()
+		switch filepath.Ext(key) {
+		case ".gpg", ".asc":
+			key = strings.TrimSuffix(key, filepath.Ext(key))
+			logger.Debugf("Decrypting key '%s'", key)
+			if payload, err = f.decrypt(payload, filepath.Ext(file.Name()) == ".asc"); err != nil {
+				return nil, fmt.Errorf("unable to decrypt '%s': %s", file.Name(), err)
+			}
+		default:
+			logger.Warnf("Key '%s' is stored in plaintext!", key)
+		}
+		data[key] = payload
+	}
+	return data, nil
+}
+
+// decrypt OpenPGP message, using key-ring when configured, and passphrase.
+func (f *FileSecretSource) decrypt(payload []byte, armored bool) ([]byte, error) {
+	var keyRing openpgp.EntityList
+	var reader io.Reader = bytes.NewReader(payload)
+	var md *openpgp.MessageDetails
+	var err error
+
+	if f.keyRing != "" {
+		if keyRing, err = f.loadKeyRing(); err != nil {
+			return nil, err
+		}
+	}
+	if armored {
+		var block *armor.Block
+		if block, err = armor.Decode(reader); err != nil {
+			return nil, err
+		}
+		reader = block.Body
+	}
+
+	if md, err = openpgp.ReadMessage(reader, keyRing, f.prompt(), nil); err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(md.UnverifiedBody)
+}
+
+// prompt returns the function employed to provide passphrase, it's tried only once, since the
+// passphrase is not going to change between attempts.
+func (f *FileSecretSource) prompt() openpgp.PromptFunction {
+	tried := false
+	return func(keys []openpgp.Key, symmetric bool) ([]byte, error) {
+		if tried || f.passphrase == "" {
+			return nil, fmt.Errorf("unable to decrypt using informed passphrase")
+		}
+		tried = true
+
+		if symmetric {
+			return []byte(f.passphrase), nil
+		}
+		for _, key := range keys {
+			if key.PrivateKey != nil && key.PrivateKey.Encrypted {
+				if err := key.PrivateKey.Decrypt([]byte(f.passphrase)); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return nil, nil
+	}
+}
+
+// loadKeyRing read key-ring file, armored or binary.
+func (f *FileSecretSource) loadKeyRing() (openpgp.EntityList, error) {
+	var keyRing openpgp.EntityList
+	var payload []byte
+	var err error
+
+	if payload, err = ioutil.ReadFile(f.keyRing); err != nil {
+		return nil, err
+	}
+	if keyRing, err = openpgp.ReadArmoredKeyRing(bytes.NewReader(payload)); err == nil {
+		return keyRing, nil
+	}
+	return openpgp.ReadKeyRing(bytes.NewReader(payload))
+}
+
+// NewFileSecretSource instantiate a file based secret source.
+func NewFileSecretSource(dir, keyRing, passphrase string) *FileSecretSource {
+	return &FileSecretSource{
+		logger:     log.WithFields(log.Fields{"type": "fileSecretSource", "dir": dir}),
+		dir:        dir,
+		keyRing:    keyRing,
+		passphrase: passphrase,
+	}
+}
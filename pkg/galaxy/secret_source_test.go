@@ -0,0 +1,129 @@
+package galaxy
+
+import (
+	"bytes"
+	"crypto"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	"golang.org/x/crypto/openpgp/packet"
+)
+
+const fileSecretSourcePassphrase = "galaxy"
+
+// fakeSecretsDir creates a temporary secrets directory, with "secret/data/kube/tls" path.
+func fakeSecretsDir(t *testing.T) (string, string) {
+	dir, err := ioutil.TempDir("", "galaxy-secrets")
+	assert.Nil(t, err)
+	secretDir := filepath.Join(dir, "secret/data/kube/tls")
+	assert.Nil(t, os.MkdirAll(secretDir, 0700))
+	return dir, secretDir
+}
+
+// fakeKeyRing creates a new gpg entity, saving the armored private key-ring on informed dir.
+func fakeKeyRing(t *testing.T, dir string) (*openpgp.Entity, string) {
+	config := &packet.Config{DefaultHash: crypto.SHA256}
+	entity, err := openpgp.NewEntity("galaxy", "test", "galaxy@localhost", config)
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PrivateKeyType, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, entity.SerializePrivate(w, nil))
+	assert.Nil(t, w.Close())
+
+	keyRing := filepath.Join(dir, "keyring.asc")
+	assert.Nil(t, ioutil.WriteFile(keyRing, buf.Bytes(), 0600))
+	return entity, keyRing
+}
+
+// writeEncrypted encrypt payload using informed function, and write it on file.
+func writeEncrypted(t *testing.T, path string, payload []byte, fn func(io.Writer) io.WriteCloser) {
+	var buf bytes.Buffer
+	w := fn(&buf)
+	_, err := w.Write(payload)
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+	assert.Nil(t, ioutil.WriteFile(path, buf.Bytes(), 0600))
+}
+
+func TestFileSecretSourceReadPlaintext(t *testing.T) {
+	dir, secretDir := fakeSecretsDir(t)
+	defer os.RemoveAll(dir)
+
+	err := ioutil.WriteFile(filepath.Join(secretDir, "tls.crt"), []byte("certificate"), 0600)
+	assert.Nil(t, err)
+
+	f := NewFileSecretSource(dir, "", "")
+	data, err := f.Read("secret/data/kube/tls")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string][]byte{"tls.crt": []byte("certificate")}, data)
+
+	_, err = f.Read("secret/data/not/found")
+	assert.NotNil(t, err)
+}
+
+func TestFileSecretSourceReadKeyRing(t *testing.T) {
+	dir, secretDir := fakeSecretsDir(t)
+	defer os.RemoveAll(dir)
+
+	entity, keyRing := fakeKeyRing(t, dir)
+	writeEncrypted(t, filepath.Join(secretDir, "tls.key.gpg"), []byte("key"),
+		func(buf io.Writer) io.WriteCloser {
+			w, err := openpgp.Encrypt(buf, []*openpgp.Entity{entity}, nil, nil, nil)
+			assert.Nil(t, err)
+			return w
+		})
+
+	f := NewFileSecretSource(dir, keyRing, "")
+	data, err := f.Read("secret/data/kube/tls")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string][]byte{"tls.key": []byte("key")}, data)
+
+	// without key-ring and passphrase the file can't be decrypted
+	f = NewFileSecretSource(dir, "", "")
+	_, err = f.Read("secret/data/kube/tls")
+	assert.NotNil(t, err)
+}
+
+func TestFileSecretSourceReadSymmetric(t *testing.T) {
+	dir, secretDir := fakeSecretsDir(t)
+	defer os.RemoveAll(dir)
+
+	writeEncrypted(t, filepath.Join(secretDir, "tls.crt.asc"), []byte("certificate"),
+		func(buf io.Writer) io.WriteCloser {
+			a, err := armor.Encode(buf, "PGP MESSAGE", nil)
+			assert.Nil(t, err)
+			w, err := openpgp.SymmetricallyEncrypt(a, []byte(fileSecretSourcePassphrase), nil, nil)
+			assert.Nil(t, err)
+			return &armoredWriter{WriteCloser: w, armor: a}
+		})
+
+	f := NewFileSecretSource(dir, "", fileSecretSourcePassphrase)
+	data, err := f.Read("secret/data/kube/tls")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string][]byte{"tls.crt": []byte("certificate")}, data)
+
+	f = NewFileSecretSource(dir, "", "wrong")
+	_, err = f.Read("secret/data/kube/tls")
+	assert.NotNil(t, err)
+}
+
+// armoredWriter closes encryption and armor writers in sequence.
+type armoredWriter struct {
+	io.WriteCloser
+	armor io.WriteCloser
+}
+
+func (a *armoredWriter) Close() error {
+	if err := a.WriteCloser.Close(); err != nil {
+		return err
+	}
+	return a.armor.Close()
+}
@@ -0,0 +1,124 @@
+package galaxy
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	coreclient "k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset/typed/core/internalversion"
+)
+
+// SecretsApplier copy secrets from a secret source into Kubernetes secrets, per namespace.
+type SecretsApplier interface {
+	Bootstrap(ns string, dryRun bool) error
+	Apply() error
+	Source() SecretSource
+	Close()
+}
+
+// SecretsHandler manage copying data from a generic secret source to Kubernetes secrets, following
+// secret manifests. Vault backed environments are handled by VaultHandler instead.
+type SecretsHandler struct {
+	logger  *log.Entry               // logger
+	source  SecretSource             // secret source
+	secrets coreclient.SecretsGetter // kubernetes secrets client
+	ctxs    []*Context               // slice of context instances
+	ns      string                   // target namespace
+	dryRun  bool                     // dry-run flag
+}
+
+// Bootstrap set target namespace and dry-run mode.
+func (s *SecretsHandler) Bootstrap(ns string, dryRun bool) error {
+	s.ns = ns
+	s.dryRun = dryRun
+	return nil
+}
+
+// Apply read secret data from source, and write Kubernetes secrets for every manifest in namespace.
+func (s *SecretsHandler) Apply() error {
+	var err error
+
+	for _, ctx := range s.ctxs {
+		for _, secret := range ctx.Secrets[s.ns] {
+			for name, secretData := range secret.Manifest.Secrets {
+				var data map[string][]byte
+
+				if data, err = s.source.Read(secretData.Path); err != nil {
+					return err
+				}
+
+				payload := make(map[string][]byte)
+				for _, item := range secretData.Data {
+					value, found := data[item.Name]
+					if !found {
+						return fmt.Errorf("key '%s' is not found on secret path '%s' (file '%s')",
+							item.Name, secretData.Path, secret.File)
+					}
+					payload[item.Name] = value
+				}
+
+				if err = s.write(name, secretData.Type, payload); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// write create or update a Kubernetes secret with informed data.
+func (s *SecretsHandler) write(name, secretType string, data map[string][]byte) error {
+	var secret *core.Secret
+	var err error
+
+	logger := s.logger.WithFields(log.Fields{"namespace": s.ns, "secret": name, "dryRun": s.dryRun})
+	if secretType == "" {
+		secretType = string(core.SecretTypeOpaque)
+	}
+
+	if s.dryRun {
+		logger.Infof("DRY-RUN: Secret would be written with %d keys (type '%s')",
+			len(data), secretType)
+		return nil
+	}
+
+	client := s.secrets.Secrets(s.ns)
+	if secret, err = client.Get(name, metav1.GetOptions{}); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		logger.Info("Creating secret...")
+		_, err = client.Create(&core.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: s.ns},
+			Type:       core.SecretType(secretType),
+			Data:       data,
+		})
+		return err
+	}
+
+	logger.Info("Updating secret...")
+	secret.Data = data
+	_, err = client.Update(secret)
+	return err
+}
+
+// Source exposes the secret source in use.
+func (s *SecretsHandler) Source() SecretSource {
+	return s.source
+}
+
+// Close is a no-op, there is no session to be closed.
+func (s *SecretsHandler) Close() {}
+
+// NewSecretsHandler instantiate a secrets handler for informed source.
+func NewSecretsHandler(
+	source SecretSource, secrets coreclient.SecretsGetter, ctxs []*Context) *SecretsHandler {
+	return &SecretsHandler{
+		logger:  log.WithField("type", "secretsHandler"),
+		source:  source,
+		secrets: secrets,
+		ctxs:    ctxs,
+	}
+}
@@ -0,0 +1,55 @@
+package galaxy
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset/fake"
+)
+
+func TestSecretsHandlerApply(t *testing.T) {
+	dir, secretDir := fakeSecretsDir(t)
+	defer os.RemoveAll(dir)
+
+	for key, value := range map[string]string{"tls.crt": "certificate", "tls.key": "key"} {
+		err := ioutil.WriteFile(filepath.Join(secretDir, key), []byte(value), 0600)
+		assert.Nil(t, err)
+	}
+
+	ctx := NewContext()
+	err := ctx.AddFile("ns1-d", "../../test/namespaces/ns1/ingress-secret.yaml")
+	assert.Nil(t, err)
+
+	kube := fake.NewSimpleClientset()
+	s := NewSecretsHandler(NewFileSecretSource(dir, "", ""), kube.Core(), []*Context{ctx})
+
+	// dry-run does not create secrets
+	assert.Nil(t, s.Bootstrap("ns1-d", true))
+	assert.Nil(t, s.Apply())
+	_, err = kube.Core().Secrets("ns1-d").Get("ingress", metav1.GetOptions{})
+	assert.NotNil(t, err)
+
+	assert.Nil(t, s.Bootstrap("ns1-d", false))
+	assert.Nil(t, s.Apply())
+	secret, err := kube.Core().Secrets("ns1-d").Get("ingress", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, core.SecretType("kubernetes.io/tls"), secret.Type)
+	assert.Equal(t, []byte("certificate"), secret.Data["tls.crt"])
+
+	// changing source contents and applying again updates the secret
+	err = ioutil.WriteFile(filepath.Join(secretDir, "tls.key"), []byte("new-key"), 0600)
+	assert.Nil(t, err)
+	assert.Nil(t, s.Apply())
+	secret, err = kube.Core().Secrets("ns1-d").Get("ingress", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("new-key"), secret.Data["tls.key"])
+
+	// key declared in manifest, but missing on source
+	assert.Nil(t, os.Remove(filepath.Join(secretDir, "tls.key")))
+	assert.NotNil(t, s.Apply())
+}
@@ -0,0 +1,145 @@
+package galaxy
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	coreclient "k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset/typed/core/internalversion"
+)
+
+const (
+	// ManagedByLabel label employed to identify secrets managed by Galaxy.
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+	// ManagedByValue value of managed-by label.
+	ManagedByValue = "galaxy"
+	// EnvironmentLabel label carrying Galaxy environment name.
+	EnvironmentLabel = "galaxy/environment"
+	// SourceFileAnnotation annotation carrying manifest file path, relative to namespaces base
+	// directory.
+	SourceFileAnnotation = "galaxy/source-file"
+	// ManifestHashAnnotation annotation carrying secret manifest file hash.
+	ManifestHashAnnotation = "galaxy/manifest-hash"
+)
+
+// SecretsOwner tags Kubernetes secrets created by Galaxy with ownership labels and annotations, and
+// prune the tagged secrets that are no longer part of the plan.
+type SecretsOwner struct {
+	logger  *log.Entry               // logger
+	secrets coreclient.SecretsGetter // kubernetes secrets client
+	env     string                   // environment name
+	baseDir string                   // namespaces base directory
+	ctxs    []*Context               // slice of context instances
+	dryRun  bool                     // dry-run flag
+}
+
+// Tag planned secrets on namespace with ownership labels and annotations.
+func (s *SecretsOwner) Tag(ns string) error {
+	var secret *core.Secret
+	var err error
+
+	for name, file := range plannedSecrets(s.ctxs, ns) {
+		logger := s.logger.WithFields(log.Fields{"namespace": ns, "secret": name, "file": file})
+
+		if s.dryRun {
+			logger.Info("DRY-RUN: Secret would be tagged with ownership labels.")
+			continue
+		}
+		if secret, err = s.secrets.Secrets(ns).Get(name, metav1.GetOptions{}); err != nil {
+			if apierrors.IsNotFound(err) {
+				logger.Warn("Secret is not found, skipping ownership labels.")
+				continue
+			}
+			return err
+		}
+
+		if secret.Labels == nil {
+			secret.Labels = make(map[string]string)
+		}
+		if secret.Annotations == nil {
+			secret.Annotations = make(map[string]string)
+		}
+		secret.Labels[ManagedByLabel] = ManagedByValue
+		secret.Labels[EnvironmentLabel] = s.env
+		secret.Annotations[SourceFileAnnotation] = relativePath(s.baseDir, file)
+		secret.Annotations[ManifestHashAnnotation] = fmt.Sprintf("%x", sha256.Sum256(readFile(file)))
+
+		logger.Debug("Tagging secret with ownership labels...")
+		if _, err = s.secrets.Secrets(ns).Update(secret); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Prune secrets on namespace, owned by Galaxy in the same environment, and not planned anymore.
+func (s *SecretsOwner) Prune(ns string) error {
+	var list *core.SecretList
+	var err error
+
+	planned := plannedSecrets(s.ctxs, ns)
+	selector := labels.SelectorFromSet(labels.Set{
+		ManagedByLabel:   ManagedByValue,
+		EnvironmentLabel: s.env,
+	}).Add(manifestsExcluded())
+
+	logger := s.logger.WithFields(log.Fields{"namespace": ns, "selector": selector.String()})
+	logger.Info("Looking for secrets to prune...")
+
+	client := s.secrets.Secrets(ns)
+	if list, err = client.List(metav1.ListOptions{LabelSelector: selector.String()}); err != nil {
+		return err
+	}
+
+	for _, secret := range list.Items {
+		if _, found := planned[secret.Name]; found {
+			continue
+		}
+		if s.dryRun {
+			logger.Infof("DRY-RUN: Secret '%s' would be pruned (file '%s')",
+				secret.Name, secret.Annotations[SourceFileAnnotation])
+			continue
+		}
+		logger.Infof("Pruning secret '%s' (file '%s')",
+			secret.Name, secret.Annotations[SourceFileAnnotation])
+		if err = client.Delete(secret.Name, &metav1.DeleteOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// plannedSecrets map of secret names and the manifest file declaring them, on namespace.
+func plannedSecrets(ctxs []*Context, ns string) map[string]string {
+	planned := make(map[string]string)
+	for _, ctx := range ctxs {
+		for _, secret := range ctx.Secrets[ns] {
+			for name := range secret.Manifest.Secrets {
+				planned[name] = secret.File
+			}
+		}
+	}
+	return planned
+}
+
+// NewSecretsOwner instantiate secrets ownership handler for environment, source file annotations
+// are relative to namespaces base directory.
+func NewSecretsOwner(
+	secrets coreclient.SecretsGetter,
+	env, baseDir string,
+	ctxs []*Context,
+	dryRun bool,
+) *SecretsOwner {
+	return &SecretsOwner{
+		logger:  log.WithFields(log.Fields{"type": "secretsOwner", "env": env, "dryRun": dryRun}),
+		secrets: secrets,
+		env:     env,
+		baseDir: baseDir,
+		ctxs:    ctxs,
+		dryRun:  dryRun,
+	}
+}
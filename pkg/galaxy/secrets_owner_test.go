@@ -0,0 +1,51 @@
+package galaxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset/fake"
+)
+
+func TestSecretsOwnerTagAndPrune(t *testing.T) {
+	baseDir := "../../test/namespaces"
+	file := "../../test/namespaces/ns1/ingress-secret.yaml"
+	ctx := NewContext()
+	err := ctx.AddFile("ns1-d", file)
+	assert.Nil(t, err)
+
+	owned := map[string]string{ManagedByLabel: ManagedByValue, EnvironmentLabel: "dev"}
+	kube := fake.NewSimpleClientset(
+		&core.Secret{ObjectMeta: metav1.ObjectMeta{Name: "ingress", Namespace: "ns1-d"}},
+		&core.Secret{ObjectMeta: metav1.ObjectMeta{Name: "orphan", Namespace: "ns1-d", Labels: owned}},
+		&core.Secret{ObjectMeta: metav1.ObjectMeta{Name: "unmanaged", Namespace: "ns1-d"}},
+	)
+
+	// dry-run does not change secrets
+	o := NewSecretsOwner(kube.Core(), "dev", baseDir, []*Context{ctx}, true)
+	assert.Nil(t, o.Tag("ns1-d"))
+	assert.Nil(t, o.Prune("ns1-d"))
+	secret, err := kube.Core().Secrets("ns1-d").Get("ingress", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Empty(t, secret.Labels)
+	_, err = kube.Core().Secrets("ns1-d").Get("orphan", metav1.GetOptions{})
+	assert.Nil(t, err)
+
+	o = NewSecretsOwner(kube.Core(), "dev", baseDir, []*Context{ctx}, false)
+	assert.Nil(t, o.Tag("ns1-d"))
+	secret, err = kube.Core().Secrets("ns1-d").Get("ingress", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, owned, secret.Labels)
+	assert.Equal(t, "ns1/ingress-secret.yaml", secret.Annotations[SourceFileAnnotation])
+	assert.Len(t, secret.Annotations[ManifestHashAnnotation], 64)
+
+	assert.Nil(t, o.Prune("ns1-d"))
+	_, err = kube.Core().Secrets("ns1-d").Get("orphan", metav1.GetOptions{})
+	assert.NotNil(t, err)
+	_, err = kube.Core().Secrets("ns1-d").Get("ingress", metav1.GetOptions{})
+	assert.Nil(t, err)
+	_, err = kube.Core().Secrets("ns1-d").Get("unmanaged", metav1.GetOptions{})
+	assert.Nil(t, err)
+}
@@ -0,0 +1,168 @@
+package galaxy
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+
+	"github.com/ryanuber/columnize"
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	coreclient "k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset/typed/core/internalversion"
+)
+
+const (
+	// SecretKeyInSync secret key has the same content in secret source and Kubernetes.
+	SecretKeyInSync = "in-sync"
+	// SecretKeyMissing secret key is declared in manifest but not found in Kubernetes.
+	SecretKeyMissing = "missing"
+	// SecretKeyOutdated secret key content differs between secret source and Kubernetes.
+	SecretKeyOutdated = "outdated"
+	// SecretKeyExtra secret key is found in Kubernetes but it's not declared in manifest.
+	SecretKeyExtra = "extra"
+	// SecretKeyNotInSource secret key is declared in manifest but not found in secret source.
+	SecretKeyNotInSource = "not-in-source"
+)
+
+// SecretKeyStatus drift status of a single key in a secret.
+type SecretKeyStatus struct {
+	Namespace string // kubernetes namespace
+	Secret    string // kubernetes secret name
+	Key       string // secret data key
+	State     string // drift state
+	File      string // secret manifest file
+}
+
+// SecretsStatus compares secret source contents with Kubernetes secrets created from secret manifests, by
+// content hash, values are never exposed.
+type SecretsStatus struct {
+	logger  *log.Entry               // logger
+	source  SecretSource             // secret source, vault or local files
+	secrets coreclient.SecretsGetter // kubernetes secrets client
+	ctxs    []*Context               // slice of context instances
+}
+
+// Inspect secret manifests on informed namespace, returning the status of each key.
+func (s *SecretsStatus) Inspect(ns string) ([]SecretKeyStatus, error) {
+	var statuses []SecretKeyStatus
+
+	for _, ctx := range s.ctxs {
+		for _, secret := range ctx.Secrets[ns] {
+			for name, secretData := range secret.Manifest.Secrets {
+				var keys []string
+				for _, data := range secretData.Data {
+					keys = append(keys, data.Name)
+				}
+
+				inspected, err := s.inspectSecret(ns, name, secretData.Path, keys)
+				if err != nil {
+					return nil, err
+				}
+				for _, status := range inspected {
+					status.File = secret.File
+					statuses = append(statuses, status)
+				}
+			}
+		}
+	}
+
+	return statuses, nil
+}
+
+// inspectSecret compare a single secret, reading source path and Kubernetes secret.
+func (s *SecretsStatus) inspectSecret(ns, name, path string, keys []string) ([]SecretKeyStatus, error) {
+	var sourceData map[string][]byte
+	var kubeSecret *core.Secret
+	var statuses []SecretKeyStatus
+	var err error
+
+	logger := s.logger.WithFields(log.Fields{"namespace": ns, "secret": name, "path": path})
+	logger.Info("Inspecting secret...")
+
+	if sourceData, err = s.source.Read(path); err != nil {
+		return nil, err
+	}
+	if kubeSecret, err = s.secrets.Secrets(ns).Get(name, metav1.GetOptions{}); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		logger.Warn("Secret is not found in Kubernetes!")
+		kubeSecret = &core.Secret{}
+	}
+
+	for _, key := range keys {
+		status := SecretKeyStatus{Namespace: ns, Secret: name, Key: key, State: SecretKeyInSync}
+		sourceValue, inSource := sourceData[key]
+		kubeValue, inKube := kubeSecret.Data[key]
+
+		switch {
+		case !inSource:
+			status.State = SecretKeyNotInSource
+		case !inKube:
+			status.State = SecretKeyMissing
+		case sha256.Sum256(sourceValue) != sha256.Sum256(kubeValue):
+			status.State = SecretKeyOutdated
+		}
+		logger.Debugf("Key '%s' is '%s'", key, status.State)
+		statuses = append(statuses, status)
+	}
+
+	for key := range kubeSecret.Data {
+		if !stringSliceContains(keys, key) {
+			logger.Debugf("Key '%s' is not declared in manifest", key)
+			statuses = append(statuses, SecretKeyStatus{
+				Namespace: ns, Secret: name, Key: key, State: SecretKeyExtra,
+			})
+		}
+	}
+
+	return statuses, nil
+}
+
+// SecretsInSync checks if all informed statuses are in sync.
+func SecretsInSync(statuses []SecretKeyStatus) bool {
+	for _, status := range statuses {
+		if status.State != SecretKeyInSync {
+			return false
+		}
+	}
+	return true
+}
+
+// SecretsStatusTable format statuses as a table, sorted by namespace, secret and key. Informed slice
+// is not modified.
+func SecretsStatusTable(statuses []SecretKeyStatus) string {
+	sorted := make([]SecretKeyStatus, len(statuses))
+	copy(sorted, statuses)
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Secret != b.Secret {
+			return a.Secret < b.Secret
+		}
+		return a.Key < b.Key
+	})
+
+	lines := []string{"NAMESPACE | SECRET | KEY | STATE | FILE"}
+	for _, status := range sorted {
+		lines = append(lines, fmt.Sprintf("%s | %s | %s | %s | %s",
+			status.Namespace, status.Secret, status.Key, status.State, status.File,
+		))
+	}
+	return columnize.SimpleFormat(lines)
+}
+
+// NewSecretsStatus instantiate a new secrets status inspector.
+func NewSecretsStatus(
+	source SecretSource, secrets coreclient.SecretsGetter, ctxs []*Context) *SecretsStatus {
+	return &SecretsStatus{
+		logger:  log.WithField("type", "secretsStatus"),
+		source:  source,
+		secrets: secrets,
+		ctxs:    ctxs,
+	}
+}
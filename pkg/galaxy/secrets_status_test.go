@@ -0,0 +1,49 @@
+package galaxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	"k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset/fake"
+)
+
+func TestSecretsStatusInspect(t *testing.T) {
+	server := fakeVault(t)
+	defer server.Close()
+
+	ctx := NewContext()
+	err := ctx.AddFile("ns1-d", "../../test/namespaces/ns1/ingress-secret.yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(ctx.Secrets["ns1-d"]))
+
+	kube := fake.NewSimpleClientset(&core.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingress", Namespace: "ns1-d"},
+		Data: map[string][]byte{
+			"tls.crt": []byte("certificate"),
+			"extra":   []byte("extra"),
+		},
+	})
+
+	s := NewSecretsStatus(fakeVaultClient(t, server.URL), kube.Core(), []*Context{ctx})
+	statuses, err := s.Inspect("ns1-d")
+	assert.Nil(t, err)
+
+	states := make(map[string]string)
+	for _, status := range statuses {
+		states[status.Key] = status.State
+	}
+	assert.Equal(t, map[string]string{
+		"tls.crt": SecretKeyInSync,
+		"tls.key": SecretKeyMissing,
+		"extra":   SecretKeyExtra,
+	}, states)
+	assert.False(t, SecretsInSync(statuses))
+
+	inspected := append([]SecretKeyStatus{}, statuses...)
+	table := SecretsStatusTable(statuses)
+	t.Logf("Secrets status:\n%s", table)
+	assert.NotContains(t, table, "certificate")
+	assert.Equal(t, inspected, statuses)
+}
@@ -0,0 +1,148 @@
+package galaxy
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// SecretsValidationFail validation issues stop apply.
+	SecretsValidationFail = "fail"
+	// SecretsValidationWarn validation issues are logged as warnings.
+	SecretsValidationWarn = "warn"
+	// SecretsValidationSkip secrets are not validated.
+	SecretsValidationSkip = "skip"
+)
+
+// requiredSecretKeys keys required per Kubernetes secret type.
+var requiredSecretKeys = map[string][]string{
+	"kubernetes.io/tls":              {"tls.crt", "tls.key"},
+	"kubernetes.io/dockercfg":        {".dockercfg"},
+	"kubernetes.io/dockerconfigjson": {".dockerconfigjson"},
+	"kubernetes.io/ssh-auth":         {"ssh-privatekey"},
+}
+
+// SecretIssue validation issue found on a secret.
+type SecretIssue struct {
+	Namespace string // kubernetes namespace
+	Secret    string // kubernetes secret name
+	File      string // secret manifest file
+	Message   string // issue description
+}
+
+// String representation of issue.
+func (s SecretIssue) String() string {
+	return fmt.Sprintf("secret '%s/%s' (file '%s'): %s", s.Namespace, s.Secret, s.File, s.Message)
+}
+
+// SecretsValidator inspect secret source data before apply, making sure required keys are present
+// and TLS certificates and keys are valid.
+type SecretsValidator struct {
+	logger *log.Entry   // logger
+	source SecretSource // secret source
+	ctxs   []*Context   // slice of context instances
+}
+
+// Validate secrets declared on namespace, returning the issues found. Errors reading the secret
+// source are issues as well, so the validation policy decides whether apply stops.
+func (s *SecretsValidator) Validate(ns string) ([]SecretIssue, error) {
+	var issues []SecretIssue
+
+	for _, ctx := range s.ctxs {
+		for _, secret := range ctx.Secrets[ns] {
+			for name, secretData := range secret.Manifest.Secrets {
+				var data map[string][]byte
+				var keys []string
+				var err error
+
+				logger := s.logger.WithFields(log.Fields{"namespace": ns, "secret": name})
+				logger.Info("Validating secret...")
+
+				if data, err = s.source.Read(secretData.Path); err != nil {
+					issues = append(issues, SecretIssue{
+						Namespace: ns,
+						Secret:    name,
+						File:      secret.File,
+						Message:   fmt.Sprintf("unable to read secret source: %s", err),
+					})
+					continue
+				}
+				for _, item := range secretData.Data {
+					keys = append(keys, item.Name)
+				}
+
+				for _, message := range validateSecretData(secretData.Type, keys, data) {
+					issues = append(issues, SecretIssue{
+						Namespace: ns, Secret: name, File: secret.File, Message: message,
+					})
+				}
+				if secretData.Type == "kubernetes.io/tls" {
+					if cert, err := parseCertificate(data["tls.crt"]); err == nil {
+						logger.Infof("Certificate '%s' expires at '%s' (%d days)",
+							cert.Subject.CommonName, cert.NotAfter.Format(time.RFC3339),
+							daysUntil(cert.NotAfter))
+					}
+				}
+			}
+		}
+	}
+	return issues, nil
+}
+
+// validateSecretData check declared and required keys are present, and validate TLS data, returning
+// a list of issues.
+func validateSecretData(secretType string, keys []string, data map[string][]byte) []string {
+	var messages []string
+
+	for _, key := range keys {
+		if _, found := data[key]; !found {
+			messages = append(messages, fmt.Sprintf("key '%s' is not found on secret source", key))
+		}
+	}
+	for _, key := range requiredSecretKeys[secretType] {
+		if !stringSliceContains(keys, key) {
+			messages = append(messages, fmt.Sprintf("key '%s' is required by type '%s', but not declared",
+				key, secretType))
+		}
+	}
+	if secretType != "kubernetes.io/tls" || len(data["tls.crt"]) == 0 || len(data["tls.key"]) == 0 {
+		return messages
+	}
+
+	if _, err := tls.X509KeyPair(data["tls.crt"], data["tls.key"]); err != nil {
+		messages = append(messages, fmt.Sprintf("invalid certificate and key pair: %s", err))
+	}
+	if cert, err := parseCertificate(data["tls.crt"]); err == nil && time.Now().After(cert.NotAfter) {
+		messages = append(messages, fmt.Sprintf("certificate has expired at '%s'",
+			cert.NotAfter.Format(time.RFC3339)))
+	}
+	return messages
+}
+
+// parseCertificate parse the first PEM encoded certificate.
+func parseCertificate(payload []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(payload)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no PEM encoded certificate found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// daysUntil amount of days until informed time, negative when in the past.
+func daysUntil(t time.Time) int {
+	return int(time.Until(t).Hours() / 24)
+}
+
+// NewSecretsValidator instantiate a secrets validator.
+func NewSecretsValidator(source SecretSource, ctxs []*Context) *SecretsValidator {
+	return &SecretsValidator{
+		logger: log.WithField("type", "secretsValidator"),
+		source: source,
+		ctxs:   ctxs,
+	}
+}
@@ -0,0 +1,93 @@
+package galaxy
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeCertificate generates a self-signed certificate and key, PEM encoded.
+func fakeCertificate(t *testing.T, cn string, notAfter time.Time) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		Issuer:       pkix.Name{CommonName: cn},
+		DNSNames:     []string{cn},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+func TestSecretsValidatorValidateSecretData(t *testing.T) {
+	cert, key := fakeCertificate(t, "galaxy.local", time.Now().Add(24*time.Hour))
+	_, otherKey := fakeCertificate(t, "galaxy.local", time.Now().Add(24*time.Hour))
+	expiredCert, expiredKey := fakeCertificate(t, "galaxy.local", time.Now().Add(-24*time.Hour))
+	tlsKeys := []string{"tls.crt", "tls.key"}
+
+	assert.Empty(t, validateSecretData("kubernetes.io/tls", tlsKeys, map[string][]byte{
+		"tls.crt": cert, "tls.key": key,
+	}))
+	assert.Len(t, validateSecretData("kubernetes.io/tls", tlsKeys, map[string][]byte{
+		"tls.crt": cert, "tls.key": otherKey,
+	}), 1)
+	assert.Len(t, validateSecretData("kubernetes.io/tls", tlsKeys, map[string][]byte{
+		"tls.crt": expiredCert, "tls.key": expiredKey,
+	}), 1)
+	// missing on source, and not declared in manifest
+	assert.Len(t, validateSecretData("kubernetes.io/tls", []string{"tls.crt"}, map[string][]byte{}), 2)
+
+	assert.Empty(t, validateSecretData("Opaque", []string{"a"}, map[string][]byte{"a": []byte("a")}))
+	assert.Len(t, validateSecretData("Opaque", []string{"a", "b"}, map[string][]byte{"a": {}}), 1)
+}
+
+func TestSecretsValidatorValidate(t *testing.T) {
+	dir, secretDir := fakeSecretsDir(t)
+	defer os.RemoveAll(dir)
+
+	cert, key := fakeCertificate(t, "galaxy.local", time.Now().Add(24*time.Hour))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(secretDir, "tls.crt"), cert, 0600))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(secretDir, "tls.key"), key, 0600))
+
+	ctx := NewContext()
+	err := ctx.AddFile("ns1-d", "../../test/namespaces/ns1/ingress-secret.yaml")
+	assert.Nil(t, err)
+
+	v := NewSecretsValidator(NewFileSecretSource(dir, "", ""), []*Context{ctx})
+	issues, err := v.Validate("ns1-d")
+	assert.Nil(t, err)
+	assert.Empty(t, issues)
+
+	assert.Nil(t, os.Remove(filepath.Join(secretDir, "tls.key")))
+	issues, err = v.Validate("ns1-d")
+	assert.Nil(t, err)
+	assert.Len(t, issues, 1)
+	assert.Contains(t, issues[0].String(), "tls.key")
+
+	// errors reading secret source are reported as issues
+	assert.Nil(t, os.RemoveAll(secretDir))
+	issues, err = v.Validate("ns1-d")
+	assert.Nil(t, err)
+	assert.Len(t, issues, 1)
+	assert.Contains(t, issues[0].String(), "unable to read secret source")
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
@@ -45,6 +46,24 @@ func isDir(dirPath string) bool {
 	return stat.IsDir()
 }
 
+// relativePath path relative to base directory, so the same file is referred the same way no matter
+// where galaxy is executed from. When not possible, path is returned as is.
+func relativePath(baseDir, filePath string) string {
+	var absBaseDir, absFilePath, rel string
+	var err error
+
+	if absBaseDir, err = filepath.Abs(baseDir); err != nil {
+		return filePath
+	}
+	if absFilePath, err = filepath.Abs(filePath); err != nil {
+		return filePath
+	}
+	if rel, err = filepath.Rel(absBaseDir, absFilePath); err != nil {
+		return filePath
+	}
+	return rel
+}
+
 // formatSlice pretty print a string slice using commas.
 func formatSlice(slice []string) string {
 	return fmt.Sprintf("[%s]", strings.Join(slice, ", "))
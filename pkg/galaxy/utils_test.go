@@ -21,3 +21,10 @@ func TestUtilsIsDir(t *testing.T) {
 func TestUtilsStringSliceContains(t *testing.T) {
 	assert.True(t, stringSliceContains([]string{"a", "b", "c"}, "b"))
 }
+
+func TestUtilsRelativePath(t *testing.T) {
+	assert.Equal(t, "ns1/app1.yaml", relativePath("../../test/namespaces",
+		"../../test/namespaces/ns1/app1.yaml"))
+	assert.Equal(t, "ns1/app1.yaml", relativePath("../../test/namespaces/",
+		"../../test/../test/namespaces/ns1/app1.yaml"))
+}
@@ -0,0 +1,213 @@
+package galaxy
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	vaultapi "github.com/hashicorp/vault/api"
+	log "github.com/sirupsen/logrus"
+)
+
+// VaultClient wrapper for Vault API client, responsible for authentication and keeping the session
+// alive during the whole apply.
+type VaultClient struct {
+	logger  *log.Entry          // logger
+	cfg     *VaultHandlerConfig // vault related configuration
+	kubeCfg *KubernetesConfig   // kubernetes configuration
+	auth    *vaultapi.Secret    // authentication secret, used for renewal
+	renewer *vaultapi.Renewer   // token renewer
+	Client  *vaultapi.Client    // vault api client
+}
+
+// Load instantiate the Vault API client against configured address.
+func (v *VaultClient) Load() error {
+	var err error
+
+	config := vaultapi.DefaultConfig()
+	config.Address = v.cfg.VaultAddr
+
+	v.logger.Info("Creating a new Vault API client...")
+	if v.Client, err = vaultapi.NewClient(config); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Login authenticate against Vault using the configured method, in order of precedence: token,
+// Kubernetes auth method (in-cluster only), or AppRole.
+func (v *VaultClient) Login() error {
+	var err error
+
+	switch {
+	case v.cfg.VaultToken != "":
+		err = v.TokenLogin()
+	case v.cfg.UseKubernetesAuth(v.kubeCfg.InCluster):
+		_, err = v.KubernetesLogin()
+	default:
+		_, err = v.AppRoleLogin()
+	}
+	return err
+}
+
+// TokenLogin use informed token, looking it up in order to know if it's renewable.
+func (v *VaultClient) TokenLogin() error {
+	var secret *vaultapi.Secret
+	var renewable bool
+	var ttl time.Duration
+	var err error
+
+	v.logger.Info("Using Vault token authentication...")
+	v.Client.SetToken(v.cfg.VaultToken)
+
+	if secret, err = v.Client.Auth().Token().LookupSelf(); err != nil {
+		return err
+	}
+	if renewable, err = secret.TokenIsRenewable(); err != nil {
+		return err
+	}
+	if ttl, err = secret.TokenTTL(); err != nil {
+		return err
+	}
+
+	v.auth = &vaultapi.Secret{Auth: &vaultapi.SecretAuth{
+		ClientToken:   v.cfg.VaultToken,
+		Renewable:     renewable,
+		LeaseDuration: int(ttl.Seconds()),
+	}}
+	return nil
+}
+
+// KubernetesLogin authenticate against Vault Kubernetes auth method, using the service-account
+// token (JWT) mounted in the pod. Returns the Vault client token.
+func (v *VaultClient) KubernetesLogin() (string, error) {
+	var jwt []byte
+	var err error
+
+	v.logger.Infof("Reading service-account token from '%s'", v.cfg.VaultKubeTokenPath)
+	if jwt, err = ioutil.ReadFile(v.cfg.VaultKubeTokenPath); err != nil {
+		return "", err
+	}
+
+	loginPath := fmt.Sprintf("auth/%s/login", strings.Trim(v.cfg.VaultKubeAuthPath, "/"))
+	return v.login(loginPath, map[string]interface{}{
+		"role": v.cfg.VaultKubeRole,
+		"jwt":  strings.TrimSpace(string(jwt)),
+	})
+}
+
+// AppRoleLogin authenticate against Vault AppRole auth method. Returns the Vault client token.
+func (v *VaultClient) AppRoleLogin() (string, error) {
+	return v.login("auth/approle/login", map[string]interface{}{
+		"role_id":   v.cfg.VaultRoleID,
+		"secret_id": v.cfg.VaultSecretID,
+	})
+}
+
+// login execute the login request against informed path, and keep the resulting token.
+func (v *VaultClient) login(loginPath string, payload map[string]interface{}) (string, error) {
+	var secret *vaultapi.Secret
+	var err error
+
+	v.logger.Infof("Authenticating on Vault via '%s'", loginPath)
+	if secret, err = v.Client.Logical().Write(loginPath, payload); err != nil {
+		return "", err
+	}
+	if secret == nil || secret.Auth == nil || secret.Auth.ClientToken == "" {
+		return "", fmt.Errorf("no authentication data returned by Vault on '%s'", loginPath)
+	}
+
+	v.auth = secret
+	v.Client.SetToken(secret.Auth.ClientToken)
+	return secret.Auth.ClientToken, nil
+}
+
+// Read secret data from Vault path, unwrapping key-value version 2 payload when needed.
+func (v *VaultClient) Read(path string) (map[string][]byte, error) {
+	var secret *vaultapi.Secret
+	var err error
+
+	v.logger.Debugf("Reading secret from path '%s'", path)
+	if secret, err = v.Client.Logical().Read(path); err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("no secret data found on Vault path '%s'", path)
+	}
+
+	payload := secret.Data
+	// key-value version 2 keeps secret data under "data", along with "metadata"
+	if inner, ok := secret.Data["data"].(map[string]interface{}); ok {
+		if _, found := secret.Data["metadata"]; found {
+			payload = inner
+		}
+	}
+
+	data := make(map[string][]byte)
+	for key, value := range payload {
+		switch value := value.(type) {
+		case string:
+			data[key] = []byte(value)
+		default:
+			data[key] = []byte(fmt.Sprintf("%v", value))
+		}
+	}
+	return data, nil
+}
+
+// Token exposes the current Vault token.
+func (v *VaultClient) Token() string {
+	return v.Client.Token()
+}
+
+// StartRenewal of Vault token in the background, when token is renewable.
+func (v *VaultClient) StartRenewal() error {
+	var err error
+
+	if v.auth == nil || v.auth.Auth == nil || !v.auth.Auth.Renewable {
+		v.logger.Info("Vault token is not renewable, skipping renewal.")
+		return nil
+	}
+
+	v.logger.Infof("Renewing Vault token in background (ttl %d seconds)", v.auth.Auth.LeaseDuration)
+	if v.renewer, err = v.Client.NewRenewer(&vaultapi.RenewerInput{Secret: v.auth}); err != nil {
+		return err
+	}
+
+	go v.renewer.Renew()
+	go v.watchRenewal(v.renewer)
+	return nil
+}
+
+// watchRenewal logs renewal events until renewer is done.
+func (v *VaultClient) watchRenewal(renewer *vaultapi.Renewer) {
+	for {
+		select {
+		case err := <-renewer.DoneCh():
+			if err != nil {
+				v.logger.Errorf("Vault token renewal stopped: '%s'", err)
+			}
+			return
+		case renewal := <-renewer.RenewCh():
+			v.logger.Debugf("Vault token renewed at '%s'", renewal.RenewedAt)
+		}
+	}
+}
+
+// Stop renewing Vault token.
+func (v *VaultClient) Stop() {
+	if v.renewer != nil {
+		v.logger.Info("Stopping Vault token renewal.")
+		v.renewer.Stop()
+	}
+}
+
+// NewVaultClient instantiate a new Vault API client wrapper.
+func NewVaultClient(cfg *VaultHandlerConfig, kubeCfg *KubernetesConfig) *VaultClient {
+	return &VaultClient{
+		logger:  log.WithFields(log.Fields{"type": "vaultClient", "vaultAddr": cfg.VaultAddr}),
+		cfg:     cfg,
+		kubeCfg: kubeCfg,
+	}
+}
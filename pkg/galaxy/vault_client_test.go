@@ -0,0 +1,186 @@
+package galaxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const fakeVaultToken = "s.fake-vault-token"
+const fakeVaultJWT = "fake.service-account.jwt"
+const fakeVaultRole = "galaxy"
+const fakeVaultRoleID = "fake-role-id"
+const fakeVaultSecretID = "fake-secret-id"
+
+var vaultClient *VaultClient
+
+// fakeVaultRenewals counts the amount of token renewals on fake-vault.
+var fakeVaultRenewals int32
+
+// fakeVault creates a HTTP server mimicking Vault authentication endpoints.
+func fakeVault(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+
+		t.Logf("Fake-Vault request: '%s %s'", r.Method, r.URL.Path)
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+
+		switch r.URL.Path {
+		case "/v1/auth/kubernetes/login":
+			if payload["role"] != fakeVaultRole || payload["jwt"] != fakeVaultJWT {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `{"errors":["permission denied"]}`)
+				return
+			}
+			fmt.Fprintf(w, `{"auth":{"client_token":"%s","lease_duration":60,"renewable":true}}`,
+				fakeVaultToken)
+		case "/v1/auth/approle/login":
+			if payload["role_id"] != fakeVaultRoleID || payload["secret_id"] != fakeVaultSecretID {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `{"errors":["permission denied"]}`)
+				return
+			}
+			fmt.Fprintf(w, `{"auth":{"client_token":"%s","lease_duration":60,"renewable":true}}`,
+				fakeVaultToken)
+		case "/v1/secret/data/kube/tls":
+			fmt.Fprint(w, `{"data":{"data":{"tls.crt":"certificate","tls.key":"key"},"metadata":{}}}`)
+		case "/v1/auth/token/lookup-self":
+			fmt.Fprintf(w, `{"data":{"id":"%s","ttl":0,"renewable":false}}`, r.Header.Get("X-Vault-Token"))
+		case "/v1/auth/token/renew-self":
+			atomic.AddInt32(&fakeVaultRenewals, 1)
+			fmt.Fprintf(w, `{"auth":{"client_token":"%s","lease_duration":60,"renewable":true}}`,
+				r.Header.Get("X-Vault-Token"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errors":[]}`)
+		}
+	}))
+}
+
+// fakeServiceAccountToken writes a temporary file with a fake JWT.
+func fakeServiceAccountToken(t *testing.T) string {
+	f, err := ioutil.TempFile("", "galaxy-sa-token")
+	assert.Nil(t, err)
+	_, err = f.WriteString(fmt.Sprintf("%s\n", fakeVaultJWT))
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+	return f.Name()
+}
+
+// fakeVaultClient instantiate and load a client against fake-vault.
+func fakeVaultClient(t *testing.T, addr string) *VaultClient {
+	cfg := NewConfig()
+	cfg.VaultAddr = addr
+
+	v := NewVaultClient(cfg.VaultHandlerConfig, cfg.KubernetesConfig)
+	err := v.Load()
+	assert.Nil(t, err)
+	return v
+}
+
+func TestVaultClientNew(t *testing.T) {
+	cfg := NewConfig()
+	vaultClient = NewVaultClient(cfg.VaultHandlerConfig, cfg.KubernetesConfig)
+
+	assert.NotNil(t, vaultClient)
+	assert.False(t, cfg.VaultHandlerConfig.UseKubernetesAuth(true))
+	cfg.VaultKubeRole = fakeVaultRole
+	assert.True(t, cfg.VaultHandlerConfig.UseKubernetesAuth(true))
+	assert.False(t, cfg.VaultHandlerConfig.UseKubernetesAuth(false))
+}
+
+func TestVaultClientKubernetesLogin(t *testing.T) {
+	server := fakeVault(t)
+	defer server.Close()
+
+	tokenPath := fakeServiceAccountToken(t)
+	defer os.Remove(tokenPath)
+
+	vaultClient = fakeVaultClient(t, server.URL)
+	vaultClient.cfg.VaultKubeRole = fakeVaultRole
+	vaultClient.cfg.VaultKubeTokenPath = tokenPath
+	vaultClient.kubeCfg.InCluster = true
+
+	err := vaultClient.Login()
+	assert.Nil(t, err)
+	assert.Equal(t, fakeVaultToken, vaultClient.Token())
+
+	vaultClient.cfg.VaultKubeRole = "unknown"
+	_, err = vaultClient.KubernetesLogin()
+	assert.NotNil(t, err)
+}
+
+func TestVaultClientAppRoleLogin(t *testing.T) {
+	server := fakeVault(t)
+	defer server.Close()
+
+	vaultClient = fakeVaultClient(t, server.URL)
+	vaultClient.cfg.VaultRoleID = fakeVaultRoleID
+	vaultClient.cfg.VaultSecretID = fakeVaultSecretID
+
+	err := vaultClient.Login()
+	assert.Nil(t, err)
+	assert.Equal(t, fakeVaultToken, vaultClient.Token())
+}
+
+func TestVaultClientTokenLogin(t *testing.T) {
+	server := fakeVault(t)
+	defer server.Close()
+
+	vaultClient = fakeVaultClient(t, server.URL)
+	vaultClient.cfg.VaultToken = "root-token"
+
+	err := vaultClient.Login()
+	assert.Nil(t, err)
+	assert.Equal(t, "root-token", vaultClient.Token())
+
+	// root token is not renewable, renewal is skipped
+	err = vaultClient.StartRenewal()
+	assert.Nil(t, err)
+	assert.Nil(t, vaultClient.renewer)
+}
+
+func TestVaultClientStartRenewal(t *testing.T) {
+	server := fakeVault(t)
+	defer server.Close()
+
+	vaultClient = fakeVaultClient(t, server.URL)
+	vaultClient.cfg.VaultRoleID = fakeVaultRoleID
+	vaultClient.cfg.VaultSecretID = fakeVaultSecretID
+
+	err := vaultClient.Login()
+	assert.Nil(t, err)
+
+	atomic.StoreInt32(&fakeVaultRenewals, 0)
+	err = vaultClient.StartRenewal()
+	assert.Nil(t, err)
+	defer vaultClient.Stop()
+
+	for i := 0; i < 50 && atomic.LoadInt32(&fakeVaultRenewals) == 0; i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	assert.True(t, atomic.LoadInt32(&fakeVaultRenewals) > 0)
+}
+
+func TestVaultClientRead(t *testing.T) {
+	server := fakeVault(t)
+	defer server.Close()
+
+	vaultClient = fakeVaultClient(t, server.URL)
+
+	data, err := vaultClient.Read("secret/data/kube/tls")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("certificate"), data["tls.crt"])
+	assert.Equal(t, []byte("key"), data["tls.key"])
+
+	_, err = vaultClient.Read("secret/data/not/found")
+	assert.NotNil(t, err)
+}
@@ -1,6 +1,9 @@
 package galaxy
 
 import (
+	"fmt"
+	"os"
+
 	log "github.com/sirupsen/logrus"
 
 	vh "github.com/otaviof/vault-handler/pkg/vault-handler"
@@ -8,12 +11,14 @@ import (
 
 // VaultHandler manage copying data from Vault to Kubernetes secrets.
 type VaultHandler struct {
-	logger     *log.Entry          // logger
-	cfg        *VaultHandlerConfig // vault-handler configuration
-	kubeCfg    *KubernetesConfig   // kubernetes configuration
-	handlerCfg *vh.Config          // handler configuration
-	handler    *vh.Handler         // handler instance
-	ctxs       []*Context          // slice of context instances
+	logger         *log.Entry          // logger
+	cfg            *VaultHandlerConfig // vault-handler configuration
+	kubeCfg        *KubernetesConfig   // kubernetes configuration
+	vaultClient    *VaultClient        // authenticated vault client, shared with other clients
+	handlerCfg     *vh.Config          // handler configuration
+	handler        *vh.Handler         // handler instance
+	ctxs           []*Context          // slice of context instances
+	kubeConfigFile string              // temporary kube-config, when api server is informed
 }
 
 // Apply rollout secrets copy from Vault to Kubernetes.
@@ -28,10 +33,42 @@ func (v *VaultHandler) Apply() error {
 	return nil
 }
 
-// Bootstrap instantiate handler and execute configuration validation and authentication steps.
+// Source exposes the authenticated Vault client as secret source, nil before authentication.
+func (v *VaultHandler) Source() SecretSource {
+	if v.vaultClient == nil {
+		return nil
+	}
+	return v.vaultClient
+}
+
+// Close remove temporary kube-config, when created. Vault session is owned by shared clients, and
+// closed with them.
+func (v *VaultHandler) Close() {
+	if v.kubeConfigFile == "" {
+		return
+	}
+	if err := os.Remove(v.kubeConfigFile); err != nil {
+		v.logger.Warnf("Unable to remove temporary kube-config '%s': %s", v.kubeConfigFile, err)
+	}
+	v.kubeConfigFile = ""
+}
+
+// Bootstrap instantiate handler and execute configuration validation steps, reusing the token
+// obtained during authentication. When Kubernetes API server is informed, vault-handler reads a
+// temporary kube-config generated with the same server, token and CA certificate.
 func (v *VaultHandler) Bootstrap(ns string, dryRun bool) error {
 	var err error
 
+	if v.vaultClient == nil {
+		return fmt.Errorf("vault session is not authenticated, can't bootstrap namespace '%s'", ns)
+	}
+	if !v.kubeCfg.InCluster && v.kubeCfg.KubeServer != "" && v.kubeConfigFile == "" {
+		if v.kubeConfigFile, err = writeExplicitKubeConfig(v.kubeCfg); err != nil {
+			return err
+		}
+		v.logger.Debugf("Using temporary kube-config '%s'", v.kubeConfigFile)
+	}
+
 	v.handlerCfg = v.setupVaultHandlerConfig(ns, dryRun)
 	if err = v.handlerCfg.Validate(); err != nil {
 		return err
@@ -48,16 +85,18 @@ func (v *VaultHandler) Bootstrap(ns string, dryRun bool) error {
 
 // setupVaultHandlerConfig create a vault-handler configuration object based on input config.
 func (v *VaultHandler) setupVaultHandlerConfig(ns string, dryRun bool) *vh.Config {
+	kubeConfig, kubeContext := v.kubeCfg.KubeConfig, v.kubeCfg.KubeContext
+	if v.kubeConfigFile != "" {
+		kubeConfig, kubeContext = v.kubeConfigFile, explicitContextName
+	}
 	return &vh.Config{
-		Context:       v.kubeCfg.KubeContext,
-		DryRun:        dryRun,
-		InCluster:     v.kubeCfg.InCluster,
-		KubeConfig:    v.kubeCfg.KubeConfig,
-		Namespace:     ns,
-		VaultAddr:     v.cfg.VaultAddr,
-		VaultRoleID:   v.cfg.VaultRoleID,
-		VaultSecretID: v.cfg.VaultSecretID,
-		VaultToken:    v.cfg.VaultToken,
+		Context:    kubeContext,
+		DryRun:     dryRun,
+		InCluster:  v.kubeCfg.InCluster,
+		KubeConfig: kubeConfig,
+		Namespace:  ns,
+		VaultAddr:  v.cfg.VaultAddr,
+		VaultToken: v.vaultClient.Token(),
 	}
 }
 
@@ -79,12 +118,18 @@ func (v *VaultHandler) pickManifests(ns string) []*vh.Manifest {
 	return manifests
 }
 
-// NewVaultHandler creates a new vault-handler instance.
-func NewVaultHandler(cfg *VaultHandlerConfig, kubeCfg *KubernetesConfig, ctxs []*Context) *VaultHandler {
+// NewVaultHandler creates a new vault-handler instance, using informed authenticated Vault client.
+func NewVaultHandler(
+	cfg *VaultHandlerConfig,
+	kubeCfg *KubernetesConfig,
+	vaultClient *VaultClient,
+	ctxs []*Context,
+) *VaultHandler {
 	return &VaultHandler{
-		logger:  log.WithField("type", "vaultHandler"),
-		cfg:     cfg,
-		kubeCfg: kubeCfg,
-		ctxs:    ctxs,
+		logger:      log.WithField("type", "vaultHandler"),
+		cfg:         cfg,
+		kubeCfg:     kubeCfg,
+		vaultClient: vaultClient,
+		ctxs:        ctxs,
 	}
 }
@@ -48,7 +48,8 @@ func prepare(t *testing.T) {
 	err = k.Load()
 	assert.Nil(t, err)
 
-	h = galaxy.NewHelmClient(cfg.HelmHome, cfg.TillerNamespace, cfg.TillerPort, cfg.TillerTimeout, k)
+	h = galaxy.NewHelmClient(
+		cfg.HelmHome, cfg.TillerNamespace, cfg.TillerPort, cfg.TillerTimeout, nil, k)
 	err = h.Load()
 	assert.Nil(t, err)
 }